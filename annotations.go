@@ -0,0 +1,361 @@
+package sweet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterReporter("annotations", func() Plugin { return NewAnnotationsReporter() })
+}
+
+const (
+	annotationsAuto   = "auto"
+	annotationsGitHub = "github"
+	annotationsGitLab = "gitlab"
+)
+
+// codeQualityIssue is an entry in a GitLab Code Quality report, which GitLab
+// shows inline in merge requests.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// annotationsReporter reports failures in a way CI services can show next to
+// the code in a pull or merge request. On GitHub Actions it writes workflow
+// commands to stdout and a summary of the results to the job summary, and on
+// GitLab it writes a Code Quality report.
+type annotationsReporter struct {
+	format      string
+	codeQuality string
+	pkg         string
+
+	out    io.Writer
+	getenv func(string) string
+	root   string
+
+	stats      *statsPlugin
+	suiteTimes map[string]time.Duration
+	failures   []*annotationFailure
+}
+
+type annotationFailure struct {
+	Name    string
+	File    string
+	Line    int
+	Message string
+	Body    string
+
+	// Quarantined failures are reported as warnings since they don't fail
+	// the run.
+	Quarantined bool
+}
+
+// NewAnnotationsReporter creates a reporter annotating failures for GitHub
+// Actions or GitLab CI.
+func NewAnnotationsReporter() Plugin {
+	return newAnnotationsReporter()
+}
+
+func newAnnotationsReporter() *annotationsReporter {
+	return &annotationsReporter{
+		format:      annotationsAuto,
+		codeQuality: "gl-code-quality-report.json",
+		pkg:         packageImportPath(),
+
+		out:    os.Stdout,
+		getenv: os.Getenv,
+
+		stats:      newStatsPlugin(),
+		suiteTimes: make(map[string]time.Duration),
+		failures:   make([]*annotationFailure, 0),
+	}
+}
+
+func (p *annotationsReporter) Name() string {
+	return "Annotations Reporter"
+}
+
+func (p *annotationsReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "annotations",
+		Options: map[string]*PluginOption{
+			"format": {
+				Help:    "CI service to annotate failures for, auto detects it from the environment",
+				Default: annotationsAuto,
+				Type:    OptionEnum,
+				Values:  []string{annotationsAuto, annotationsGitHub, annotationsGitLab},
+			},
+			"codequality": {
+				Help:    "Path of the GitLab Code Quality report, relative to the module root, shared files are merged",
+				Default: "gl-code-quality-report.json",
+				Type:    OptionPath,
+			},
+		},
+	}
+}
+
+func (p *annotationsReporter) SetOption(name, value string) {
+	switch name {
+	case "format":
+		p.format = value
+	case "codequality":
+		p.codeQuality = value
+	}
+}
+
+func (p *annotationsReporter) Starting() {
+	if p.format == annotationsAuto {
+		switch {
+		case p.getenv("GITHUB_ACTIONS") == "true":
+			p.format = annotationsGitHub
+		case p.getenv("GITLAB_CI") == "true":
+			p.format = annotationsGitLab
+		}
+	}
+
+	// Annotations need paths relative to the root of the repository
+	p.root = p.getenv("GITHUB_WORKSPACE")
+	if p.format == annotationsGitLab {
+		p.root = p.getenv("CI_PROJECT_DIR")
+	}
+	if p.root == "" {
+		if wd, err := os.Getwd(); err == nil {
+			p.root = findRepoRoot(wd)
+		}
+	}
+}
+
+func (p *annotationsReporter) Finished() {
+	var err error
+	switch p.format {
+	case annotationsGitHub:
+		err = p.writeStepSummary()
+	case annotationsGitLab:
+		err = p.writeCodeQuality()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write CI annotations: %s\n", err)
+	}
+}
+
+func (p *annotationsReporter) SuiteStarting(suite string) {
+	p.stats.SuiteStarting(suite)
+}
+func (p *annotationsReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	p.suiteTimes[suite] = stats.Time
+}
+
+func (p *annotationsReporter) TestStarting(testName *TestName) {}
+func (p *annotationsReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.stats.TestPassed(testName, stats)
+}
+func (p *annotationsReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.stats.TestSkipped(testName, stats)
+}
+func (p *annotationsReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.stats.TestFailed(testName, stats)
+	p.addFailure(testName, stats)
+}
+func (p *annotationsReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	p.stats.TestQuarantined(testName, stats)
+	p.addFailure(testName, stats)
+}
+
+func (p *annotationsReporter) addFailure(testName *TestName, stats *TestFailedStats) {
+	// The failed subtests are annotated themselves
+	if onlySubtestsFailed(stats) {
+		return
+	}
+
+	failure := &annotationFailure{
+		Name:        testName.String(),
+		Message:     stats.Message,
+		Body:        failureBody(stats),
+		Quarantined: stats.Quarantined,
+	}
+	for _, frame := range stats.Frames {
+		if !frame.Hidden {
+			failure.File = p.relativePath(frame.File)
+			failure.Line = frame.Line
+			break
+		}
+	}
+	p.failures = append(p.failures, failure)
+
+	if p.format == annotationsGitHub {
+		p.writeGitHubAnnotation(failure)
+	}
+}
+
+func (p *annotationsReporter) writeGitHubAnnotation(failure *annotationFailure) {
+	props := make([]string, 0, 3)
+	if failure.File != "" {
+		props = append(props,
+			"file="+githubEscapeProperty(failure.File),
+			fmt.Sprintf("line=%d", failure.Line),
+		)
+	}
+	command := "error"
+	title := failure.Name + " failed"
+	if failure.Quarantined {
+		command = "warning"
+		title += " (quarantined)"
+	}
+	props = append(props, "title="+githubEscapeProperty(title))
+
+	fmt.Fprintf(p.out, "::%s %s::%s\n",
+		command, strings.Join(props, ","), githubEscapeData(failure.Message))
+}
+
+// writeStepSummary adds a Markdown summary of the results to the job summary
+// if GitHub has provided a file for it. The summary is shared by every package
+// so it's appended to.
+func (p *annotationsReporter) writeStepSummary() error {
+	summaryPath := p.getenv("GITHUB_STEP_SUMMARY")
+	if summaryPath == "" {
+		return nil
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "### Test results for `%s`\n\n", p.pkg)
+	fmt.Fprintf(&summary, "| Suite | Passed | Failed | Skipped | Time |\n")
+	fmt.Fprintf(&summary, "| --- | ---: | ---: | ---: | ---: |\n")
+	for _, suite := range p.stats.Suites() {
+		status := ":white_check_mark:"
+		if suite.Failed > 0 {
+			status = ":x:"
+		}
+		fmt.Fprintf(&summary, "| %s %s | %d | %d | %d | %.2fs |\n",
+			status, suite.Name, suite.Passed, suite.Failed, suite.Skipped,
+			p.suiteTimes[suite.Name].Seconds())
+	}
+	fmt.Fprintf(&summary, "\n")
+
+	for _, failure := range p.failures {
+		name := failure.Name
+		if failure.Quarantined {
+			name += " (quarantined)"
+		}
+		fmt.Fprintf(&summary, "<details><summary>%s</summary>\n\n```\n%s\n```\n\n</details>\n\n",
+			name, failure.Body)
+	}
+
+	f, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(summary.String())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// writeCodeQuality writes the failures to a GitLab Code Quality report. Every
+// package can write to the same report, so the issues from a previous run of
+// this package are replaced and the rest are kept.
+func (p *annotationsReporter) writeCodeQuality() error {
+	if err := os.MkdirAll(filepath.Dir(p.codeQuality), 0755); err != nil {
+		return err
+	}
+
+	return withFileLock(p.codeQuality, func() error {
+		existing := make([]*codeQualityIssue, 0)
+
+		data, err := ioutil.ReadFile(p.codeQuality)
+		if err == nil {
+			err = json.Unmarshal(data, &existing)
+			if err != nil {
+				return fmt.Errorf("existing report could not be read: %s", err)
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		issues := make([]*codeQualityIssue, 0, len(existing)+len(p.failures))
+		for _, issue := range existing {
+			if issue.CheckName != p.pkg {
+				issues = append(issues, issue)
+			}
+		}
+		for _, failure := range p.failures {
+			fingerprint := sha1.Sum([]byte(p.pkg + "/" + failure.Name))
+			description := failure.Name + " failed: " + firstLine(failure.Message)
+			severity := "major"
+			if failure.Quarantined {
+				description = failure.Name + " failed (quarantined): " + firstLine(failure.Message)
+				severity = "info"
+			}
+			issues = append(issues, &codeQualityIssue{
+				Description: description,
+				CheckName:   p.pkg,
+				Fingerprint: hex.EncodeToString(fingerprint[:]),
+				Severity:    severity,
+				Location: codeQualityLocation{
+					Path:  failure.File,
+					Lines: codeQualityLines{Begin: failure.Line},
+				},
+			})
+		}
+
+		data, err = json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(p.codeQuality, append(data, '\n'))
+	})
+}
+
+// relativePath makes a failure's file path relative to the repository root,
+// which is how CI services refer to files.
+func (p *annotationsReporter) relativePath(file string) string {
+	if p.root == "" {
+		return file
+	}
+
+	rel, err := filepath.Rel(p.root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// githubEscapeData escapes the message of a workflow command.
+func githubEscapeData(value string) string {
+	value = strings.Replace(value, "%", "%25", -1)
+	value = strings.Replace(value, "\r", "%0D", -1)
+	return strings.Replace(value, "\n", "%0A", -1)
+}
+
+// githubEscapeProperty escapes a property value of a workflow command, which
+// also can't contain the characters separating properties.
+func githubEscapeProperty(value string) string {
+	value = githubEscapeData(value)
+	value = strings.Replace(value, ":", "%3A", -1)
+	return strings.Replace(value, ",", "%2C", -1)
+}
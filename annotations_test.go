@@ -0,0 +1,153 @@
+package sweet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type AnnotationsSuite struct{}
+
+func (s *AnnotationsSuite) runReporter(p *annotationsReporter, env map[string]string) {
+	p.getenv = func(name string) string {
+		return env[name]
+	}
+
+	passName := newTestName("MySuite", []string{"TestPass"})
+	failName := newTestName("MySuite", []string{"TestFail"})
+	subName := newSubtestName(failName, "Sub")
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestPassed(passName, &TestPassedStats{Time: time.Second})
+	p.TestFailed(subName, &TestFailedStats{
+		Name:    subName,
+		Message: "Expected\n    <int>: 1\nto equal\n    <int>: 2",
+		Frames: []*TestFailedFrame{
+			{File: "/src/pkg/my_test.go", Line: 20},
+		},
+	})
+	p.TestFailed(failName, &TestFailedStats{
+		Name:           failName,
+		FailedSubtests: []*TestName{subName},
+	})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: 1500 * time.Millisecond})
+	p.Finished()
+}
+
+func (s *AnnotationsSuite) TestGitHub(t T) {
+	dir, err := ioutil.TempDir("", "sweet-annotations")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	summaryPath := filepath.Join(dir, "summary.md")
+	Expect(ioutil.WriteFile(summaryPath, []byte("existing\n"), 0644)).To(Succeed())
+
+	out := &bytes.Buffer{}
+	p := newAnnotationsReporter()
+	p.pkg = "example.com/pkg"
+	p.out = out
+	s.runReporter(p, map[string]string{
+		"GITHUB_ACTIONS":      "true",
+		"GITHUB_WORKSPACE":    "/src",
+		"GITHUB_STEP_SUMMARY": summaryPath,
+	})
+
+	Expect(out.String()).To(Equal(
+		"::error file=pkg/my_test.go,line=20,title=MySuite/TestFail/Sub failed::" +
+			"Expected%0A    <int>: 1%0Ato equal%0A    <int>: 2\n",
+	))
+
+	summary, err := ioutil.ReadFile(summaryPath)
+	Expect(err).To(BeNil())
+	Expect(string(summary)).To(Equal("existing\n" +
+		"### Test results for `example.com/pkg`\n\n" +
+		"| Suite | Passed | Failed | Skipped | Time |\n" +
+		"| --- | ---: | ---: | ---: | ---: |\n" +
+		"| :x: MySuite | 1 | 1 | 0 | 1.50s |\n\n" +
+		"<details><summary>MySuite/TestFail/Sub</summary>\n\n" +
+		"```\n/src/pkg/my_test.go:20\n\nExpected\n    <int>: 1\nto equal\n    <int>: 2\n```\n\n" +
+		"</details>\n\n",
+	))
+}
+
+func (s *AnnotationsSuite) TestGitLab(t T) {
+	dir, err := ioutil.TempDir("", "sweet-annotations")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	reportPath := filepath.Join(dir, "codequality.json")
+	env := map[string]string{
+		"GITLAB_CI":      "true",
+		"CI_PROJECT_DIR": "/src",
+	}
+
+	for _, pkg := range []string{"example.com/first", "example.com/second", "example.com/first"} {
+		out := &bytes.Buffer{}
+		p := newAnnotationsReporter()
+		p.pkg = pkg
+		p.out = out
+		p.SetOption("codequality", reportPath)
+		s.runReporter(p, env)
+
+		Expect(out.String()).To(BeEmpty())
+	}
+
+	data, err := ioutil.ReadFile(reportPath)
+	Expect(err).To(BeNil())
+
+	issues := make([]*codeQualityIssue, 0)
+	Expect(json.Unmarshal(data, &issues)).To(Succeed())
+	Expect(issues).To(HaveLen(2))
+	Expect(issues[0].CheckName).To(Equal("example.com/second"))
+	Expect(issues[1].CheckName).To(Equal("example.com/first"))
+	Expect(issues[1].Description).To(Equal("MySuite/TestFail/Sub failed: Expected"))
+	Expect(issues[1].Location.Path).To(Equal("pkg/my_test.go"))
+	Expect(issues[1].Location.Lines.Begin).To(Equal(20))
+	Expect(issues[0].Fingerprint).NotTo(Equal(issues[1].Fingerprint))
+}
+
+func (s *AnnotationsSuite) TestNoCI(t T) {
+	out := &bytes.Buffer{}
+	p := newAnnotationsReporter()
+	p.out = out
+	s.runReporter(p, map[string]string{})
+
+	Expect(out.String()).To(BeEmpty())
+}
+
+func (s *AnnotationsSuite) TestGitHubEscape(t T) {
+	Expect(githubEscapeData("100%\r\nnext: a,b")).To(Equal("100%25%0D%0Anext: a,b"))
+	Expect(githubEscapeProperty("100%\r\nnext: a,b")).To(Equal("100%25%0D%0Anext%3A a%2Cb"))
+}
+
+func (s *AnnotationsSuite) TestQuarantined(t T) {
+	out := &bytes.Buffer{}
+	p := newAnnotationsReporter()
+	p.out = out
+	p.getenv = func(name string) string {
+		if name == "GITHUB_ACTIONS" {
+			return "true"
+		}
+		return ""
+	}
+
+	testName := newTestName("MySuite", []string{"TestFlaky"})
+
+	p.Starting()
+	p.TestQuarantined(testName, &TestFailedStats{
+		Name:        testName,
+		Message:     "boom",
+		Quarantined: true,
+	})
+	p.Finished()
+
+	Expect(out.String()).To(Equal("::warning title=MySuite/TestFlaky failed (quarantined)::boom\n"))
+	Expect(p.stats.Suites()[0].Failed).To(BeZero())
+	Expect(p.stats.Suites()[0].Quarantined).To(Equal(int64(1)))
+}
@@ -0,0 +1,223 @@
+package sweet
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// configFileNames are the names of the project configuration files sweet
+// looks for, in order, in each directory from the package directory up.
+var configFileNames = []string{
+	"sweet.yaml",
+	"sweet.yml",
+	".sweet.yaml",
+	".sweet.yml",
+	"sweet.toml",
+	".sweet.toml",
+}
+
+// goTestDefaultTimeout is the timeout "go test" passes to every test binary
+// when -timeout isn't used.
+const goTestDefaultTimeout = "10m0s"
+
+// config is a project level configuration file that provides defaults for
+// sweet's flags and plugin options. Anything provided on the command line
+// takes priority over the file.
+type config struct {
+	Path string `yaml:"-" toml:"-"`
+
+	// Flags are default values for sweet flags, such as "reporter" for
+	// -sweet.reporter. Names containing a dot, such as "test.v", are used as
+	// the full flag name.
+	Flags   map[string]interface{} `yaml:"flags" toml:"flags"`
+	Include []string               `yaml:"include" toml:"include"`
+	Exclude []string               `yaml:"exclude" toml:"exclude"`
+	// Plugins are plugin option values keyed by the plugin prefix and then
+	// the option name.
+	Plugins map[string]map[string]interface{} `yaml:"plugins" toml:"plugins"`
+	// Timeout is used for -test.timeout if a timeout wasn't provided on the
+	// command line. "go test" always passes one, so its 10m default is
+	// treated as not being provided. Timeouts longer than 10m still need
+	// -timeout with "go test" since it stops the binary itself otherwise.
+	Timeout string `yaml:"timeout" toml:"timeout"`
+
+	// applied holds the names of the flags Apply set from the file.
+	applied map[string]bool
+}
+
+// findConfig looks for a configuration file starting in dir and walking up to
+// the root of the file system, returning an empty path if none were found.
+func findConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", path, err)
+	}
+	cfg.Path = path
+
+	return cfg, nil
+}
+
+// loadProjectConfig loads the file provided with -sweet.config or the first
+// one found from the working directory up. It returns nil if there's no
+// configuration to use.
+func loadProjectConfig() (*config, error) {
+	path := *flagConfig
+	if path == "none" {
+		return nil, nil
+	}
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		path = findConfig(wd)
+		if path == "" {
+			return nil, nil
+		}
+	}
+
+	return loadConfig(path)
+}
+
+// Apply sets the flags from the configuration that weren't provided on the
+// command line.
+func (c *config) Apply(fs *flag.FlagSet) error {
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	c.applied = make(map[string]bool)
+
+	names := make([]string, 0, len(c.Flags))
+	for name := range c.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flagName := name
+		if !strings.Contains(flagName, ".") {
+			flagName = "sweet." + flagName
+		}
+		if setFlags[flagName] {
+			continue
+		}
+		if fs.Lookup(flagName) == nil {
+			return fmt.Errorf("unknown flag \"%s\" in %s", name, c.Path)
+		}
+
+		for _, value := range configValues(c.Flags[name]) {
+			if err := fs.Set(flagName, value); err != nil {
+				return fmt.Errorf("invalid value for flag \"%s\" in %s: %s", name, c.Path, err)
+			}
+		}
+		c.applied[flagName] = true
+	}
+
+	if len(c.Include) > 0 && !setFlags["sweet.include"] {
+		flagInclude = append(flagInclude, c.Include...)
+	}
+	if len(c.Exclude) > 0 && !setFlags["sweet.exclude"] {
+		flagExclude = append(flagExclude, c.Exclude...)
+	}
+
+	if c.Timeout != "" {
+		// "go test" always provides a timeout, so if it's the default we
+		// treat it as not being set.
+		timeoutFlag := fs.Lookup("test.timeout")
+		if timeoutFlag != nil &&
+			(!setFlags["test.timeout"] || timeoutFlag.Value.String() == goTestDefaultTimeout) {
+			if err := fs.Set("test.timeout", c.Timeout); err != nil {
+				return fmt.Errorf("invalid timeout in %s: %s", c.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Applied returns true if the flag's value was set from the configuration
+// file rather than the command line.
+func (c *config) Applied(flagName string) bool {
+	return c != nil && c.applied[flagName]
+}
+
+// PluginValues returns the option values for the plugin with the given prefix.
+func (c *config) PluginValues(prefix string) map[string]string {
+	if c == nil {
+		return nil
+	}
+
+	pluginOpts, ok := c.Plugins[prefix]
+	if !ok {
+		return nil
+	}
+
+	res := make(map[string]string)
+	for name, value := range pluginOpts {
+		values := configValues(value)
+		if len(values) > 0 {
+			res[name] = values[len(values)-1]
+		}
+	}
+
+	return res
+}
+
+// configValues converts a value from a configuration file into the flag values
+// it represents. Lists are treated as providing a flag more than once.
+func configValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			res = append(res, fmt.Sprint(item))
+		}
+		return res
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,130 @@
+package sweet
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type ConfigSuite struct{}
+
+func (s *ConfigSuite) TestFindConfig(t T) {
+	root, err := ioutil.TempDir("", "sweet-config")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(root)
+
+	pkgDir := filepath.Join(root, "a", "b")
+	Expect(os.MkdirAll(pkgDir, 0755)).To(Succeed())
+
+	Expect(findConfig(pkgDir)).To(Equal(""))
+
+	rootConfig := filepath.Join(root, ".sweet.toml")
+	Expect(ioutil.WriteFile(rootConfig, []byte(""), 0644)).To(Succeed())
+	Expect(findConfig(pkgDir)).To(Equal(rootConfig))
+
+	// The closest configuration file wins
+	nearConfig := filepath.Join(root, "a", "sweet.yaml")
+	Expect(ioutil.WriteFile(nearConfig, []byte(""), 0644)).To(Succeed())
+	Expect(findConfig(pkgDir)).To(Equal(nearConfig))
+}
+
+func (s *ConfigSuite) TestLoadYAML(t T) {
+	dir, err := ioutil.TempDir("", "sweet-config")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sweet.yaml")
+	Expect(ioutil.WriteFile(path, []byte(`
+flags:
+  reporter: dots
+  parallelsuites: true
+include:
+  - MySuite
+plugins:
+  junit:
+    output: reports/junit.xml
+    merge: true
+timeout: 5m
+`), 0644)).To(Succeed())
+
+	cfg, err := loadConfig(path)
+	Expect(err).To(BeNil())
+	Expect(cfg.Path).To(Equal(path))
+	Expect(cfg.Flags["reporter"]).To(Equal("dots"))
+	Expect(cfg.Include).To(Equal([]string{"MySuite"}))
+	Expect(cfg.Timeout).To(Equal("5m"))
+	Expect(cfg.PluginValues("junit")).To(Equal(map[string]string{
+		"output": "reports/junit.xml",
+		"merge":  "true",
+	}))
+	Expect(cfg.PluginValues("tap")).To(BeNil())
+}
+
+func (s *ConfigSuite) TestLoadTOML(t T) {
+	dir, err := ioutil.TempDir("", "sweet-config")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".sweet.toml")
+	Expect(ioutil.WriteFile(path, []byte(`
+timeout = "2m"
+
+[flags]
+reporter = "verbose"
+snippet = 3
+
+[plugins.junit]
+output = "junit.xml"
+`), 0644)).To(Succeed())
+
+	cfg, err := loadConfig(path)
+	Expect(err).To(BeNil())
+	Expect(cfg.Flags["reporter"]).To(Equal("verbose"))
+	Expect(cfg.Timeout).To(Equal("2m"))
+	Expect(cfg.PluginValues("junit")).To(Equal(map[string]string{
+		"output": "junit.xml",
+	}))
+}
+
+func (s *ConfigSuite) TestApply(t T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	reporter := fs.String("sweet.reporter", "default", "")
+	snippet := fs.Int("sweet.snippet", 0, "")
+	verbose := fs.Bool("test.v", false, "")
+	timeout := fs.Duration("test.timeout", 0, "")
+	Expect(fs.Parse([]string{"-sweet.reporter=quiet", "-test.timeout=10m0s"})).To(Succeed())
+
+	cfg := &config{
+		Path: "sweet.yaml",
+		Flags: map[string]interface{}{
+			"reporter": "dots",
+			"snippet":  3,
+			"test.v":   true,
+		},
+		Timeout: "1m",
+	}
+	Expect(cfg.Apply(fs)).To(Succeed())
+
+	// The command line wins over the configuration file
+	Expect(*reporter).To(Equal("quiet"))
+	Expect(*snippet).To(Equal(3))
+	Expect(*verbose).To(BeTrue())
+	Expect(cfg.Applied("sweet.snippet")).To(BeTrue())
+	Expect(cfg.Applied("sweet.reporter")).To(BeFalse())
+	// The timeout go test always passes doesn't count as being provided
+	Expect(*timeout).To(Equal(time.Minute))
+
+	// Any other timeout on the command line wins
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	timeout = fs.Duration("test.timeout", 0, "")
+	Expect(fs.Parse([]string{"-test.timeout=20m"})).To(Succeed())
+	Expect((&config{Timeout: "1m"}).Apply(fs)).To(Succeed())
+	Expect(*timeout).To(Equal(20 * time.Minute))
+
+	cfg.Flags = map[string]interface{}{"unknown": "value"}
+	Expect(cfg.Apply(fs)).ToNot(Succeed())
+}
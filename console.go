@@ -0,0 +1,310 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mgutz/ansi"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type consoleMode int
+
+const consoleDotsPerLine = 80
+
+const (
+	consoleDefault consoleMode = iota
+	consoleDots
+	consoleVerbose
+	consoleQuiet
+)
+
+// consoleReporter is the reporter used to write test failures and the
+// summary of results to the console.
+type consoleReporter struct {
+	mode   consoleMode
+	out    io.Writer
+	isTerm bool
+
+	stats  *statsPlugin
+	differ *differ
+
+	outLock sync.Mutex
+	// failures holds the failure output for modes that print failures
+	// after all the tests have run instead of as they happen.
+	failures []string
+	dotCount int
+}
+
+func newConsoleReporter(mode consoleMode) *consoleReporter {
+	return newConsoleReporterWriter(
+		mode,
+		os.Stdout,
+		terminal.IsTerminal(int(os.Stdout.Fd())),
+	)
+}
+
+func newConsoleReporterWriter(mode consoleMode, out io.Writer, isTerm bool) *consoleReporter {
+	return &consoleReporter{
+		mode:   mode,
+		out:    out,
+		isTerm: isTerm,
+
+		stats:  newStatsPlugin(),
+		differ: newDiffer(),
+	}
+}
+
+func (p *consoleReporter) Name() string {
+	return "Console Reporter"
+}
+
+func (p *consoleReporter) Options() *PluginOptions {
+	return nil
+}
+
+func (p *consoleReporter) SetOption(name, value string) {
+
+}
+
+func (p *consoleReporter) Starting() {
+	p.stats.Starting()
+}
+func (p *consoleReporter) SuiteStarting(suite string) {
+	p.stats.SuiteStarting(suite)
+
+	if p.mode == consoleVerbose {
+		p.print("=== SUITE %s\n", suite)
+	}
+}
+func (p *consoleReporter) TestStarting(testName *TestName) {
+	p.stats.TestStarting(testName)
+}
+func (p *consoleReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.stats.TestPassed(testName, stats)
+
+	switch p.mode {
+	case consoleDots:
+		p.dot(".", "green")
+	case consoleVerbose:
+		p.print("%s: %s (%s)\n", p.color("PASS", "green"), testName, stats.Time)
+	}
+}
+func (p *consoleReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.stats.TestFailed(testName, stats)
+
+	switch p.mode {
+	case consoleDefault:
+		p.print("%s", p.formatFailure(stats))
+	case consoleDots:
+		p.dot("F", "red")
+
+		p.outLock.Lock()
+		p.failures = append(p.failures, p.formatFailure(stats))
+		p.outLock.Unlock()
+	case consoleVerbose:
+		p.print("%s: %s (%s)\n%s", p.color("FAIL", "red"), testName, stats.Time, p.formatFailure(stats))
+	}
+}
+func (p *consoleReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	p.stats.TestQuarantined(testName, stats)
+
+	switch p.mode {
+	case consoleDefault:
+		p.print("%s", p.formatFailure(stats))
+	case consoleDots:
+		p.dot("Q", "magenta")
+
+		p.outLock.Lock()
+		p.failures = append(p.failures, p.formatFailure(stats))
+		p.outLock.Unlock()
+	case consoleVerbose:
+		p.print("%s: %s (%s)\n%s", p.color("QUARANTINED", "magenta"), testName, stats.Time, p.formatFailure(stats))
+	}
+}
+func (p *consoleReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.stats.TestSkipped(testName, stats)
+
+	switch p.mode {
+	case consoleDots:
+		p.dot("S", "yellow")
+	case consoleVerbose:
+		p.print("%s: %s (%s)\n", p.color("SKIP", "yellow"), testName, stats.Time)
+	}
+}
+func (p *consoleReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	p.stats.SuiteFinished(suite, stats)
+}
+func (p *consoleReporter) Finished() {
+	p.stats.Finished()
+
+	if p.mode == consoleQuiet {
+		return
+	}
+
+	p.outLock.Lock()
+	defer p.outLock.Unlock()
+
+	if p.mode == consoleDots {
+		if p.dotCount%consoleDotsPerLine != 0 {
+			fmt.Fprintln(p.out, "")
+		}
+		for _, failure := range p.failures {
+			fmt.Fprint(p.out, failure)
+		}
+	}
+
+	suites := p.stats.Suites()
+	if len(suites) > 0 {
+		fmt.Fprintln(p.out, "")
+		fmt.Fprintf(p.out, "Suite Results:\n")
+		fmt.Fprintf(p.out, "--------------\n")
+		totals := &suiteStats{}
+		for _, suite := range suites {
+			totalStr := fmt.Sprintf("%d", suite.Passed+suite.Failed+suite.Skipped+suite.Quarantined)
+
+			passedStr := fmt.Sprintf("%d", suite.Passed)
+			if suite.Passed > 0 {
+				passedStr = p.color(passedStr, "green")
+			}
+
+			failedStr := fmt.Sprintf("%d", suite.Failed)
+			if suite.Failed > 0 {
+				failedStr = p.color(failedStr, "red")
+			}
+
+			skippedStr := fmt.Sprintf("%d", suite.Skipped)
+			if suite.Skipped > 0 {
+				skippedStr = p.color(skippedStr, "yellow")
+			}
+
+			quarantinedStr := ""
+			if suite.Quarantined > 0 {
+				quarantinedStr = ", Quarantined: " + p.color(fmt.Sprintf("%d", suite.Quarantined), "magenta")
+			}
+
+			fmt.Fprintf(p.out, "%s - Total: %s, Passed: %s, Failed: %s, Skipped: %s%s, Time: %s\n",
+				suite.Name,
+				totalStr,
+				passedStr,
+				failedStr,
+				skippedStr,
+				quarantinedStr,
+				suite.Time.Round(time.Millisecond),
+			)
+
+			totals.Passed += suite.Passed
+			totals.Failed += suite.Failed
+			totals.Skipped += suite.Skipped
+			totals.Quarantined += suite.Quarantined
+		}
+
+		quarantinedStr := ""
+		if totals.Quarantined > 0 {
+			quarantinedStr = fmt.Sprintf(", Quarantined: %d", totals.Quarantined)
+		}
+		fmt.Fprintf(p.out, "--------------\n")
+		fmt.Fprintf(p.out, "All Suites - Total: %d, Passed: %d, Failed: %d, Skipped: %d%s, Time: %s\n",
+			totals.Passed+totals.Failed+totals.Skipped+totals.Quarantined,
+			totals.Passed,
+			totals.Failed,
+			totals.Skipped,
+			quarantinedStr,
+			p.stats.RunTime().Round(time.Millisecond),
+		)
+		fmt.Fprintln(p.out, "")
+	}
+
+	if *flagSlowest > 0 {
+		slowest := p.stats.Slowest(*flagSlowest)
+		if len(slowest) > 0 {
+			fmt.Fprintf(p.out, "Slowest Tests:\n")
+			fmt.Fprintf(p.out, "--------------\n")
+			for _, test := range slowest {
+				fmt.Fprintf(p.out, "%s (%s)\n", test.Name, test.Time.Round(time.Millisecond))
+			}
+			fmt.Fprintln(p.out, "")
+		}
+	}
+}
+
+func (p *consoleReporter) formatFailure(stats *TestFailedStats) string {
+	if onlySubtestsFailed(stats) {
+		// The subtests were already reported so there's nothing more to
+		// show for the parent.
+		return ""
+	}
+
+	var out strings.Builder
+
+	result := "FAIL"
+	if stats.Quarantined {
+		result = "QUARANTINED"
+	}
+
+	fmt.Fprintf(&out, "-------------------------------------------------\n")
+	fmt.Fprintf(&out, "%s: %s\n\n", result, stats.Name)
+
+	for _, line := range stats.Output {
+		fmt.Fprint(&out, line)
+	}
+	if len(stats.Output) > 0 {
+		fmt.Fprintf(&out, "\n\n")
+	}
+
+	for _, frame := range stats.Frames {
+		if !frame.Hidden {
+			fmt.Fprintf(&out, "%s:%d\n", displayPath(frame.File), frame.Line)
+			if frame.Source != nil {
+				fmt.Fprint(&out, frame.Source.Format(p.isTerm))
+			}
+		}
+	}
+
+	diffMessage := p.differ.ProcessMessage(stats.Message)
+
+	fmt.Fprintf(&out, "%s\n\n", diffMessage)
+
+	if stats.Panicked && *flagExtended {
+		fmt.Fprintf(&out, "%s\n", stats.Stack)
+	}
+
+	return out.String()
+}
+
+func onlySubtestsFailed(stats *TestFailedStats) bool {
+	return len(stats.FailedSubtests) > 0 &&
+		stats.Message == "" &&
+		len(stats.Frames) == 0 &&
+		len(stats.Output) == 0
+}
+
+func (p *consoleReporter) print(format string, args ...interface{}) {
+	p.outLock.Lock()
+	defer p.outLock.Unlock()
+
+	fmt.Fprintf(p.out, format, args...)
+}
+
+func (p *consoleReporter) dot(dot string, color string) {
+	p.outLock.Lock()
+	defer p.outLock.Unlock()
+
+	fmt.Fprint(p.out, p.color(dot, color))
+	p.dotCount++
+	if p.dotCount%consoleDotsPerLine == 0 {
+		fmt.Fprintln(p.out, "")
+	}
+}
+
+func (p *consoleReporter) color(value string, color string) string {
+	if !p.isTerm {
+		return value
+	}
+
+	return ansi.Color(value, color)
+}
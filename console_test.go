@@ -0,0 +1,123 @@
+package sweet
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type ConsoleSuite struct{}
+
+func (s *ConsoleSuite) runEvents(p *consoleReporter) {
+	passName := newTestName("MySuite", []string{"TestPass"})
+	failName := newTestName("MySuite", []string{"TestFail"})
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestStarting(passName)
+	p.TestPassed(passName, &TestPassedStats{Time: time.Second})
+	p.TestStarting(failName)
+	p.TestFailed(failName, &TestFailedStats{
+		Name:    failName,
+		Time:    time.Second,
+		Message: "it broke",
+		Output:  []string{"logged line"},
+		Frames: []*TestFailedFrame{
+			{File: "/src/hidden.go", Line: 10, Hidden: true},
+			{File: "/src/my_test.go", Line: 20},
+		},
+	})
+	p.TestStarting(skipName)
+	p.TestSkipped(skipName, &TestSkippedStats{Time: time.Second})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: 3 * time.Second})
+	p.Finished()
+}
+
+func (s *ConsoleSuite) TestDefault(t T) {
+	buf := &bytes.Buffer{}
+	s.runEvents(newConsoleReporterWriter(consoleDefault, buf, false))
+
+	Expect(buf.String()).To(Equal(
+		"-------------------------------------------------\n" +
+			"FAIL: MySuite/TestFail\n\n" +
+			"logged line\n\n" +
+			"my_test.go:20\n" +
+			"it broke\n\n" +
+			"\n" +
+			"Suite Results:\n" +
+			"--------------\n" +
+			"MySuite - Total: 3, Passed: 1, Failed: 1, Skipped: 1, Time: 3s\n" +
+			"--------------\n" +
+			"All Suites - Total: 3, Passed: 1, Failed: 1, Skipped: 1, Time: 0s\n" +
+			"\n",
+	))
+}
+
+func (s *ConsoleSuite) TestDots(t T) {
+	buf := &bytes.Buffer{}
+	s.runEvents(newConsoleReporterWriter(consoleDots, buf, false))
+
+	Expect(buf.String()).To(HavePrefix(".FS\n" +
+		"-------------------------------------------------\n" +
+		"FAIL: MySuite/TestFail\n\n"))
+	Expect(buf.String()).To(ContainSubstring("MySuite - Total: 3, Passed: 1, Failed: 1, Skipped: 1, Time: 3s\n"))
+}
+
+func (s *ConsoleSuite) TestVerbose(t T) {
+	buf := &bytes.Buffer{}
+	s.runEvents(newConsoleReporterWriter(consoleVerbose, buf, false))
+
+	Expect(buf.String()).To(HavePrefix("=== SUITE MySuite\n" +
+		"PASS: MySuite/TestPass (1s)\n" +
+		"FAIL: MySuite/TestFail (1s)\n" +
+		"-------------------------------------------------\n"))
+	Expect(buf.String()).To(ContainSubstring("SKIP: MySuite/TestSkip (1s)\n"))
+	Expect(buf.String()).To(ContainSubstring("MySuite - Total: 3, Passed: 1, Failed: 1, Skipped: 1, Time: 3s\n"))
+}
+
+func (s *ConsoleSuite) TestQuiet(t T) {
+	buf := &bytes.Buffer{}
+	s.runEvents(newConsoleReporterWriter(consoleQuiet, buf, false))
+
+	Expect(buf.String()).To(BeEmpty())
+}
+
+func (s *ConsoleSuite) TestNewReporters(t T) {
+	reporters, err := newReporters("dots, verbose")
+	Expect(err).To(BeNil())
+	Expect(reporters).To(HaveLen(2))
+
+	_, err = newReporters("unknown")
+	Expect(err).ToNot(BeNil())
+}
+
+func (s *ConsoleSuite) TestSlowest(t T) {
+	oldSlowest := *flagSlowest
+	*flagSlowest = 2
+	defer func() { *flagSlowest = oldSlowest }()
+
+	buf := &bytes.Buffer{}
+	p := newConsoleReporterWriter(consoleDefault, buf, false)
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	for idx, d := range []time.Duration{time.Second, 3 * time.Second, 2 * time.Second} {
+		testName := newTestName("MySuite", []string{fmt.Sprintf("Test%d", idx)})
+		p.TestPassed(testName, &TestPassedStats{Time: d})
+	}
+	p.TestPassed(
+		newSubtestName(newTestName("MySuite", []string{"Test1"}), "Sub"),
+		&TestPassedStats{Time: time.Minute},
+	)
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: 6 * time.Second})
+	p.Finished()
+
+	Expect(buf.String()).To(HaveSuffix("Slowest Tests:\n" +
+		"--------------\n" +
+		"MySuite/Test1 (3s)\n" +
+		"MySuite/Test2 (2s)\n" +
+		"\n"))
+}
@@ -0,0 +1,242 @@
+package sweet
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DependentSuite is implemented by suites where some tests depend on others
+// having passed, such as the steps of an end to end scenario. It returns the
+// names of the tests each test depends on. Dependencies always run before
+// the tests depending on them, and a test is skipped if any of its
+// dependencies fail or are skipped.
+type DependentSuite interface {
+	Dependencies() map[string][]string
+}
+
+func suiteDependencies(suite interface{}) map[string][]string {
+	if dependent, ok := suite.(DependentSuite); ok {
+		return dependent.Dependencies()
+	}
+
+	return nil
+}
+
+// checkDependencies makes sure every dependency of the suite is one of its
+// tests and that no tests depend on each other in a cycle.
+func checkDependencies(suiteName string, suite interface{}) error {
+	deps := suiteDependencies(suite)
+	if len(deps) == 0 {
+		return nil
+	}
+
+	suiteType := reflect.TypeOf(suite)
+	isTest := func(name string) bool {
+		method, ok := suiteType.MethodByName(name)
+		return ok && strings.HasPrefix(method.Name, "Test")
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !isTest(name) {
+			return fmt.Errorf("%s has dependencies but isn't a test", formatName(suiteName, name))
+		}
+		for _, dep := range deps[name] {
+			if !isTest(dep) {
+				return fmt.Errorf("%s depends on %s, which isn't a test",
+					formatName(suiteName, name), formatName(suiteName, dep))
+			}
+		}
+	}
+
+	// Walk the dependencies depth first, a test that's seen again while its
+	// own dependencies are being walked is part of a cycle.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	path := make([]string, 0)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for idx, pathName := range path {
+				if pathName == name {
+					start = idx
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("%s has a dependency cycle: %s",
+				suiteName, strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDependencies checks the dependencies of every suite that was added.
+func (s *S) checkDependencies() error {
+	for _, runner := range s.suiteRunners {
+		if err := checkDependencies(runner.name(), runner.suite); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orderDependencies moves tests after their dependencies, otherwise keeping
+// the order they're in.
+func orderDependencies(tests []int, suiteType reflect.Type, deps map[string][]string) []int {
+	if len(deps) == 0 {
+		return tests
+	}
+
+	ordered := make([]int, 0, len(tests))
+	placed := make(map[string]bool)
+
+	for len(ordered) < len(tests) {
+		found := false
+		for _, idx := range tests {
+			name := suiteType.Method(idx).Name
+			if placed[name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range deps[name] {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[name] = true
+				ordered = append(ordered, idx)
+				found = true
+				break
+			}
+		}
+
+		// Only a cycle leaves tests that can't be placed, keep them in
+		// their order rather than looping forever.
+		if !found {
+			for _, idx := range tests {
+				if !placed[suiteType.Method(idx).Name] {
+					ordered = append(ordered, idx)
+				}
+			}
+			break
+		}
+	}
+
+	return ordered
+}
+
+// selectTests returns the indexes of the test methods to run, in the order
+// they should run in, after filtering them by -sweet.rerunfailed and
+// -sweet.tags. The dependencies of any selected test are selected too so it
+// has a chance to pass.
+func (s *suiteRunner) selectTests(suiteName string, suiteType reflect.Type) []int {
+	tests := s.orderTests(suiteName, suiteType)
+	deps := suiteDependencies(s.suite)
+
+	selected := make(map[string]bool)
+	var selectTest func(name string)
+	selectTest = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, dep := range deps[name] {
+			selectTest(dep)
+		}
+	}
+	for _, idx := range tests {
+		name := suiteType.Method(idx).Name
+		if s.s.shouldRun(newTestName(suiteName, []string{name})) &&
+			matchTags(flagTags, s.testTags(name)) {
+			selectTest(name)
+		}
+	}
+
+	res := make([]int, 0, len(tests))
+	for _, idx := range tests {
+		if selected[suiteType.Method(idx).Name] {
+			res = append(res, idx)
+		}
+	}
+
+	return res
+}
+
+// unsuccessfulDependency returns the first of the test's dependencies that
+// failed or was skipped in this run, if there is one, along with whether it
+// "failed" or was "skipped".
+func (s *suiteRunner) unsuccessfulDependency(testName string) (string, string, bool) {
+	s.unsuccessfulLock.Lock()
+	defer s.unsuccessfulLock.Unlock()
+
+	for _, dep := range suiteDependencies(s.suite)[testName] {
+		if result, ok := s.unsuccessful[dep]; ok {
+			return dep, result, true
+		}
+	}
+
+	return "", "", false
+}
+
+// skipForDependency reports a test as skipped because of one of its
+// dependencies, without running it or its set up and tear down.
+func (s *suiteRunner) skipForDependency(t *testing.T, testName *TestName, reason string) {
+	wrapT := newSweetT(t, testName)
+	wrapT.runner = s
+
+	failureStats := &TestFailedStats{
+		Name:   testName,
+		Frames: make([]*TestFailedFrame, 0),
+	}
+	testStart := time.Now()
+	s.recoverTest(wrapT, failureStats, func() {
+		s.s.emitTestStarting(testName)
+
+		wrapT.Skip(reason)
+	})
+
+	wrapT.finish(func() {
+		s.reportResult(wrapT, failureStats, testStart)
+	})
+}
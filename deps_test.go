@@ -0,0 +1,80 @@
+package sweet
+
+import (
+	"reflect"
+
+	. "github.com/onsi/gomega"
+)
+
+type DepsSuite struct{}
+
+type depsScenarioSuite struct{}
+
+func (s *depsScenarioSuite) Dependencies() map[string][]string {
+	return map[string][]string{
+		"TestAddToCart": {"TestLogin"},
+		"TestCheckout":  {"TestAddToCart", "TestLogin"},
+	}
+}
+func (s *depsScenarioSuite) TestAddToCart(t T) {}
+func (s *depsScenarioSuite) TestBrowse(t T)    {}
+func (s *depsScenarioSuite) TestCheckout(t T)  {}
+func (s *depsScenarioSuite) TestLogin(t T)     {}
+
+type depsCycleSuite struct{}
+
+func (s *depsCycleSuite) Dependencies() map[string][]string {
+	return map[string][]string{
+		"TestA": {"TestB"},
+		"TestB": {"TestC"},
+		"TestC": {"TestB"},
+	}
+}
+func (s *depsCycleSuite) TestA(t T) {}
+func (s *depsCycleSuite) TestB(t T) {}
+func (s *depsCycleSuite) TestC(t T) {}
+
+type depsMissingSuite struct{}
+
+func (s *depsMissingSuite) Dependencies() map[string][]string {
+	return map[string][]string{
+		"TestA": {"TestMissing"},
+	}
+}
+func (s *depsMissingSuite) TestA(t T) {}
+
+func (s *DepsSuite) TestCheckDependencies(t T) {
+	Expect(checkDependencies("depsScenarioSuite", &depsScenarioSuite{})).To(BeNil())
+	Expect(checkDependencies("orderFirstSuite", &orderFirstSuite{})).To(BeNil())
+
+	err := checkDependencies("depsCycleSuite", &depsCycleSuite{})
+	Expect(err).ToNot(BeNil())
+	Expect(err.Error()).To(Equal("depsCycleSuite has a dependency cycle: TestB -> TestC -> TestB"))
+
+	err = checkDependencies("depsMissingSuite", &depsMissingSuite{})
+	Expect(err).ToNot(BeNil())
+	Expect(err.Error()).To(Equal("depsMissingSuite/TestA depends on depsMissingSuite/TestMissing, which isn't a test"))
+}
+
+func (s *DepsSuite) TestOrderDependencies(t T) {
+	runner := newSuiteRunner(&S{}, &depsScenarioSuite{})
+	Expect(testOrderNames(runner, "depsScenarioSuite")).To(Equal(
+		[]string{"TestBrowse", "TestLogin", "TestAddToCart", "TestCheckout"},
+	))
+}
+
+func (s *DepsSuite) TestSelectTests(t T) {
+	sweetS := &S{
+		rerun: &failedTests{
+			Failed: []string{"depsScenarioSuite/TestAddToCart"},
+		},
+	}
+	runner := newSuiteRunner(sweetS, &depsScenarioSuite{})
+	suiteType := reflect.TypeOf(runner.suite)
+
+	names := make([]string, 0)
+	for _, idx := range runner.selectTests("depsScenarioSuite", suiteType) {
+		names = append(names, suiteType.Method(idx).Name)
+	}
+	Expect(names).To(Equal([]string{"TestLogin", "TestAddToCart"}))
+}
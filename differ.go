@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"strings"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
@@ -27,6 +28,37 @@ func (d *differ) ProcessMessage(message string) string {
 }
 
 func (d *differ) gomegaDiff(message string) (string, bool) {
+	actual, expected, ok := gomegaValues(message)
+	if !ok {
+		return "", false
+	}
+
+	diffs := d.dm.DiffMain(actual, expected, true)
+	prettyDiff := d.dm.DiffPrettyText(diffs)
+
+	message += "\nDiff\n" + prettyDiff
+
+	return message, true
+}
+
+// HTMLDiff returns an HTML fragment highlighting the differences between the
+// values of a gomega comparison, meant to be shown in a <pre> element.
+func (d *differ) HTMLDiff(message string) (string, bool) {
+	actual, expected, ok := gomegaValues(message)
+	if !ok {
+		return "", false
+	}
+
+	diffs := d.dm.DiffMain(actual, expected, true)
+	prettyDiff := d.dm.DiffPrettyHtml(diffs)
+
+	// The line breaks are already preserved by the <pre>
+	return strings.Replace(prettyDiff, "&para;<br>", "\n", -1), true
+}
+
+// gomegaValues pulls the actual and expected values out of a gomega failure
+// message comparing two values. Gomega lists the actual value first.
+func gomegaValues(message string) (actual string, expected string, ok bool) {
 	var supportedExpectations = []string{
 		"to equal",
 	}
@@ -46,13 +78,13 @@ mainLoop:
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return "", false
+			return "", "", false
 		}
 
 		if lineIdx == 0 {
 			if string(line) != "Expected" {
 				// This isn't a gomega error we know
-				return "", false
+				return "", "", false
 			} else {
 				insideFirst = true
 				lineIdx++
@@ -70,7 +102,7 @@ mainLoop:
 				}
 			}
 			if len(line) > 0 && line[0] != ' ' {
-				return "", false
+				return "", "", false
 			}
 
 			firstValue += string(line) + "\n"
@@ -83,10 +115,11 @@ mainLoop:
 		lineIdx++
 	}
 
-	diffs := d.dm.DiffMain(firstValue, secondValue, true)
-	prettyDiff := d.dm.DiffPrettyText(diffs)
-
-	message += "\nDiff\n" + prettyDiff
+	// Only a message with both the values and a supported expectation
+	// between them is a comparison.
+	if !insideSecond {
+		return "", "", false
+	}
 
-	return message, true
+	return firstValue, secondValue, true
 }
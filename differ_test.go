@@ -84,3 +84,41 @@ Diff
     	[0m
 `))
 }
+
+func (s *differSuite) TestGomegaValues(t T) {
+	actual, expected, ok := gomegaValues("Expected\n    <int>: 1\nto equal\n    <int>: 2")
+	Expect(ok).To(BeTrue())
+	Expect(actual).To(Equal("    <int>: 1\n"))
+	Expect(expected).To(Equal("    <int>: 2\n"))
+
+	notComparisons := []string{
+		"",
+		"boom",
+		"something else\nwith more lines",
+		"Expected",
+		"Expected\n    <int>: 1",
+		"Expected\n    <int>: 1\n",
+		"Expected\n    <bool>: false\nto be true",
+	}
+	for _, message := range notComparisons {
+		_, _, ok = gomegaValues(message)
+		Expect(ok).To(BeFalse(), "message %q", message)
+		Expect(newDiffer().ProcessMessage(message)).To(Equal(message))
+	}
+}
+
+func (s *differSuite) TestHTMLDiff(t T) {
+	d := newDiffer()
+
+	res, ok := d.HTMLDiff("Expected\n    <string>: a<b\nto equal\n    <string>: a<c")
+	Expect(ok).To(BeTrue())
+	Expect(res).To(Equal(
+		`<span>    &lt;string&gt;: a&lt;</span>` +
+			`<del style="background:#ffe6e6;">b</del>` +
+			`<ins style="background:#e6ffe6;">c</ins>` +
+			"<span>\n</span>",
+	))
+
+	_, ok = d.HTMLDiff("not a comparison")
+	Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,243 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// eventBus delivers events to plugins. Each plugin gets its own goroutine and
+// queue so events are delivered to a plugin one at a time in the order they
+// were published, no matter how many suites are running at once, and a slow
+// plugin doesn't hold up the tests.
+type eventBus struct {
+	workers []*pluginWorker
+}
+
+func newEventBus(plugins []Plugin) *eventBus {
+	return newEventBusWriter(plugins, os.Stderr)
+}
+
+func newEventBusWriter(plugins []Plugin, errOut io.Writer) *eventBus {
+	bus := &eventBus{
+		workers: make([]*pluginWorker, 0, len(plugins)),
+	}
+	for _, plugin := range plugins {
+		bus.workers = append(bus.workers, newPluginWorker(plugin, errOut))
+	}
+
+	return bus
+}
+
+func (b *eventBus) Publish(event func(plugin Plugin)) {
+	for _, worker := range b.workers {
+		worker.Send(event)
+	}
+}
+
+// Close waits for every plugin to finish handling the events published so
+// far and stops the workers.
+func (b *eventBus) Close() {
+	for _, worker := range b.workers {
+		worker.Close()
+	}
+	for _, worker := range b.workers {
+		<-worker.done
+	}
+}
+
+type pluginWorker struct {
+	plugin Plugin
+	errOut io.Writer
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  []func(plugin Plugin)
+	closed bool
+
+	done chan struct{}
+}
+
+func newPluginWorker(plugin Plugin, errOut io.Writer) *pluginWorker {
+	w := &pluginWorker{
+		plugin: plugin,
+		errOut: errOut,
+		queue:  make([]func(plugin Plugin), 0),
+		done:   make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.lock)
+
+	go w.run()
+
+	return w
+}
+
+func (w *pluginWorker) Send(event func(plugin Plugin)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return
+	}
+	w.queue = append(w.queue, event)
+	w.cond.Signal()
+}
+
+func (w *pluginWorker) Close() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.closed = true
+	w.cond.Signal()
+}
+
+func (w *pluginWorker) run() {
+	defer close(w.done)
+
+	broken := false
+	for {
+		w.lock.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 && w.closed {
+			w.lock.Unlock()
+			return
+		}
+		event := w.queue[0]
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+		w.lock.Unlock()
+
+		// Once a plugin has panicked we can't trust its state anymore so
+		// the rest of its events are dropped.
+		if broken {
+			continue
+		}
+		broken = !w.deliver(event)
+	}
+}
+
+// deliver sends the event to the plugin, returning false if the plugin
+// panicked while handling it.
+func (w *pluginWorker) deliver(event func(plugin Plugin)) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(w.errOut,
+				"ERROR: Sweet plugin %s panicked while handling an event and has been disabled: %v\n",
+				w.plugin.Name(), r)
+			ok = false
+		}
+	}()
+
+	event(w.plugin)
+
+	return true
+}
+
+// publish sends an event to every plugin, using the event bus if the tests
+// are running or calling the plugins directly if they're not.
+func (s *S) publish(event func(plugin Plugin)) {
+	if s.bus != nil {
+		s.bus.Publish(event)
+		return
+	}
+
+	for _, plugin := range s.plugins {
+		event(plugin)
+	}
+}
+
+// These send events to each plugin implementing the interface for it.
+
+func (s *S) emitStarting() {
+	s.publish(func(plugin Plugin) {
+		if listener, ok := plugin.(RunStartingListener); ok {
+			listener.Starting()
+		}
+	})
+}
+
+func (s *S) emitFinished() {
+	s.publish(func(plugin Plugin) {
+		if listener, ok := plugin.(RunFinishedListener); ok {
+			listener.Finished()
+		}
+	})
+
+	// Finished is the last event so make sure all the plugins are done
+	// before the test binary exits.
+	if s.bus != nil {
+		s.bus.Close()
+		s.bus = nil
+	}
+}
+
+func (s *S) emitSuiteStarting(suite string, stats *SuiteStartingStats) {
+	s.publish(func(plugin Plugin) {
+		if withStats, ok := plugin.(SuiteStartingStatsListener); ok {
+			withStats.SuiteStartingWithStats(suite, stats)
+		} else if listener, ok := plugin.(SuiteListener); ok {
+			listener.SuiteStarting(suite)
+		}
+	})
+}
+
+func (s *S) emitSuiteFinished(suite string, stats *SuiteFinishedStats) {
+	s.publish(func(plugin Plugin) {
+		if listener, ok := plugin.(SuiteListener); ok {
+			listener.SuiteFinished(suite, stats)
+		}
+	})
+}
+
+func (s *S) emitTestStarting(testName *TestName) {
+	s.publish(func(plugin Plugin) {
+		if sub, ok := plugin.(SubtestListener); ok && testName.IsSubtest() {
+			sub.SubtestStarting(testName)
+		} else if listener, ok := plugin.(TestListener); ok {
+			listener.TestStarting(testName)
+		}
+	})
+}
+
+func (s *S) emitTestPassed(testName *TestName, stats *TestPassedStats) {
+	s.publish(func(plugin Plugin) {
+		if sub, ok := plugin.(SubtestListener); ok && testName.IsSubtest() {
+			sub.SubtestPassed(testName, stats)
+		} else if listener, ok := plugin.(TestListener); ok {
+			listener.TestPassed(testName, stats)
+		}
+	})
+}
+
+func (s *S) emitTestFailed(testName *TestName, stats *TestFailedStats) {
+	s.publish(func(plugin Plugin) {
+		if quarantine, ok := plugin.(QuarantineListener); ok && stats.Quarantined {
+			quarantine.TestQuarantined(testName, stats)
+		} else if sub, ok := plugin.(SubtestListener); ok && testName.IsSubtest() {
+			sub.SubtestFailed(testName, stats)
+		} else if listener, ok := plugin.(TestListener); ok {
+			listener.TestFailed(testName, stats)
+		}
+	})
+}
+
+func (s *S) emitTestSkipped(testName *TestName, stats *TestSkippedStats) {
+	s.publish(func(plugin Plugin) {
+		if sub, ok := plugin.(SubtestListener); ok && testName.IsSubtest() {
+			sub.SubtestSkipped(testName, stats)
+		} else if listener, ok := plugin.(TestListener); ok {
+			listener.TestSkipped(testName, stats)
+		}
+	})
+}
+
+func (s *S) emitTestOutput(testName *TestName, output string) {
+	s.publish(func(plugin Plugin) {
+		if listener, ok := plugin.(OutputListener); ok {
+			listener.TestOutput(testName, output)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package sweet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/gomega"
+)
+
+type EventsSuite struct{}
+
+type orderPlugin struct {
+	BasePlugin
+
+	lock   sync.Mutex
+	events []string
+}
+
+func (p *orderPlugin) Name() string { return "Order" }
+func (p *orderPlugin) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.events = append(p.events, testName.String())
+}
+
+type panicPlugin struct {
+	BasePlugin
+	calls int
+}
+
+func (p *panicPlugin) Name() string { return "Panics" }
+func (p *panicPlugin) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.calls++
+	panic("plugin broke")
+}
+
+func (s *EventsSuite) TestOrdered(t T) {
+	plugin := &orderPlugin{}
+	sw := &S{plugins: []Plugin{plugin}}
+	sw.bus = newEventBus(sw.plugins)
+
+	expected := make([]string, 0)
+	for idx := 0; idx < 100; idx++ {
+		name := newTestName("MySuite", []string{fmt.Sprintf("Test%d", idx)})
+		expected = append(expected, name.String())
+		sw.emitTestPassed(name, &TestPassedStats{})
+	}
+	sw.emitFinished()
+
+	Expect(plugin.events).To(Equal(expected))
+	Expect(sw.bus).To(BeNil())
+}
+
+func (s *EventsSuite) TestPanickingPlugin(t T) {
+	broken := &panicPlugin{}
+	working := &orderPlugin{}
+	errOut := &bytes.Buffer{}
+
+	bus := newEventBusWriter([]Plugin{broken, working}, errOut)
+	for idx := 0; idx < 3; idx++ {
+		name := newTestName("MySuite", []string{fmt.Sprintf("Test%d", idx)})
+		bus.Publish(func(plugin Plugin) {
+			plugin.(TestListener).TestPassed(name, &TestPassedStats{})
+		})
+	}
+	bus.Close()
+
+	// The broken plugin only sees the first event but the working one
+	// still gets all of them.
+	Expect(broken.calls).To(Equal(1))
+	Expect(working.events).To(HaveLen(3))
+	Expect(errOut.String()).To(ContainSubstring("Sweet plugin Panics panicked"))
+}
+
+func (s *EventsSuite) TestPublishWithoutBus(t T) {
+	plugin := &orderPlugin{}
+	sw := &S{plugins: []Plugin{plugin}}
+
+	sw.emitTestPassed(newTestName("MySuite", []string{"TestThing"}), &TestPassedStats{})
+
+	Expect(plugin.events).To(Equal([]string{"MySuite/TestThing"}))
+}
+
+type suitePlugin struct {
+	BasePlugin
+	events []string
+}
+
+func (p *suitePlugin) Name() string { return "Suites" }
+func (p *suitePlugin) SuiteStarting(suite string) {
+	p.events = append(p.events, "start:"+suite)
+}
+
+type suiteStatsPlugin struct {
+	suitePlugin
+}
+
+func (p *suiteStatsPlugin) SuiteStartingWithStats(suite string, stats *SuiteStartingStats) {
+	p.events = append(p.events, fmt.Sprintf("start:%s:%v", suite, stats.Tags))
+}
+
+func (s *EventsSuite) TestSuiteStartingStats(t T) {
+	plain := &suitePlugin{}
+	withStats := &suiteStatsPlugin{}
+	sw := &S{plugins: []Plugin{plain, withStats}}
+
+	sw.emitSuiteStarting("MySuite", &SuiteStartingStats{Tags: []string{"slow"}})
+
+	// Plugins written before the stats were added still get the event
+	Expect(plain.events).To(Equal([]string{"start:MySuite"}))
+	Expect(withStats.events).To(Equal([]string{"start:MySuite:[slow]"}))
+}
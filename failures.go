@@ -1,16 +1,27 @@
 package sweet
 
 import (
-	"os"
+	"fmt"
+	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
+	"sync"
 )
 
 var (
+	// goPackages are the standard library packages that sit between sweet
+	// and the test method. Once we reach one of these while walking the
+	// stack we're out of the test's code.
 	goPackages = []string{
 		"runtime",
 		"reflect",
 	}
+
+	hiddenPackagesLock sync.RWMutex
+	hiddenPackages     = []string{
+		"github.com/onsi/gomega",
+	}
 )
 
 type testCompletion interface{}
@@ -18,26 +29,50 @@ type testCompletion interface{}
 type failureFrame struct {
 	Filename    string
 	LineNumber  int
+	Function    string
 	HiddenFrame bool
 }
 
 type testFailed struct {
-	TestName *TestName
-	Message  string
-	Frames   []*failureFrame
+	Message string
+	Frames  []*failureFrame
+
+	// Panicked is set when the failure came from a panic that wasn't raised
+	// by sweet itself, such as a nil pointer dereference in the test.
+	Panicked   bool
+	PanicValue interface{}
+	Stack      string
 }
 
-type testSkipped struct{}
+type testSkipped struct {
+	Message string
+}
 
-func isGoPackage(path string) bool {
-	srcDelim := "src" + string(os.PathSeparator)
-	idx := strings.Index(path, srcDelim)
-	if idx < 0 {
-		return false
+func addHiddenPackages(packages ...string) {
+	hiddenPackagesLock.Lock()
+	defer hiddenPackagesLock.Unlock()
+
+	for _, pkg := range packages {
+		pkg = strings.TrimSuffix(strings.TrimSpace(pkg), "/")
+		if pkg == "" {
+			continue
+		}
+		hiddenPackages = append(hiddenPackages, pkg)
 	}
-	idx += len(srcDelim)
-	for _, pkg := range goPackages {
-		if strings.HasPrefix(path[idx:], pkg) {
+}
+
+func isHiddenPackage(pkg string) bool {
+	hiddenPackagesLock.RLock()
+	defer hiddenPackagesLock.RUnlock()
+
+	return matchesPackage(pkg, hiddenPackages)
+}
+
+// matchesPackage checks if pkg is one of the packages provided or is nested
+// under one of them.
+func matchesPackage(pkg string, packages []string) bool {
+	for _, check := range packages {
+		if pkg == check || strings.HasPrefix(pkg, check+"/") {
 			return true
 		}
 	}
@@ -45,56 +80,103 @@ func isGoPackage(path string) bool {
 	return false
 }
 
+// funcPackage returns the import path of the package a function name from
+// a stack frame belongs to, such as "github.com/onsi/gomega/internal/assertion"
+// for "github.com/onsi/gomega/internal/assertion.(*Assertion).To".
+func funcPackage(function string) string {
+	// Vendored packages show up under the vendoring package's path but we
+	// want to treat them as the package they really are.
+	if idx := strings.LastIndex(function, "/vendor/"); idx >= 0 {
+		function = function[idx+len("/vendor/"):]
+	}
+
+	lastSlash := strings.LastIndex(function, "/")
+	if lastSlash < 0 {
+		lastSlash = 0
+	}
+	dotIdx := strings.Index(function[lastSlash:], ".")
+	if dotIdx < 0 {
+		return function
+	}
+
+	return function[:lastSlash+dotIdx]
+}
+
+// isGoRootFile checks if the file is part of the Go installation the test
+// binary was built with.
+func isGoRootFile(file string) bool {
+	goRoot := runtime.GOROOT()
+	if goRoot == "" || file == "" {
+		return false
+	}
+
+	srcDir := filepath.ToSlash(filepath.Join(goRoot, "src")) + "/"
+	return strings.HasPrefix(filepath.ToSlash(file), srcDir)
+}
+
+// isStdPackage guesses if an import path is part of the standard library
+// based on the first path element not having a dot in it, like a domain
+// name would.
+func isStdPackage(pkg string) bool {
+	if pkg == "" || pkg == "main" {
+		return false
+	}
+
+	first := pkg
+	if idx := strings.Index(pkg, "/"); idx >= 0 {
+		first = pkg[:idx]
+	}
+
+	return !strings.Contains(first, ".")
+}
+
+// isGoFrame checks if the frame is part of the standard library. Files are
+// checked against GOROOT first, and if the path isn't absolute because the
+// binary was built with -trimpath we fall back to the package name.
+func isGoFrame(frame runtime.Frame) bool {
+	if isGoRootFile(frame.File) {
+		return true
+	}
+	if frame.File != "" && filepath.IsAbs(frame.File) {
+		return false
+	}
+
+	return isStdPackage(funcPackage(frame.Function))
+}
+
+// isStopFrame checks if the frame is where the test's code stops and the
+// code calling the test method starts.
+func isStopFrame(frame runtime.Frame) bool {
+	if !isGoFrame(frame) {
+		return false
+	}
+
+	return matchesPackage(funcPackage(frame.Function), goPackages)
+}
+
 func skipTest(message string) {
-	skipped := &testSkipped{}
+	skipped := &testSkipped{
+		Message: message,
+	}
 	panic(skipped)
 }
 
 func failTest(message string, callerSkip ...int) {
 	failFrames := make([]*failureFrame, 0)
 	if len(callerSkip) > 0 {
-		callIdx := callerSkip[0] + 2
-		callers := make([]uintptr, 0)
-		for {
-			pc, file, _, ok := runtime.Caller(callIdx)
-			if ok {
-				if isGoPackage(file) {
-					break
-				}
-				callers = append(callers, pc)
-				callIdx++
-			} else {
-				break
-			}
-		}
+		// runtime.Callers counts itself as a frame so skip one more than
+		// runtime.Caller would have to get to the same place.
+		callers := make([]uintptr, 100)
+		callers = callers[:runtime.Callers(callerSkip[0]+3, callers)]
 
 		frames := runtime.CallersFrames(callers)
 		for {
 			frame, more := frames.Next()
-
-			hiddenFrame := false
-			// Skip any frames that are part of the go testing package or don't actually
-			// have a function name... cuz wtf is that anyway? Seems like they're runtime
-			// package functions.
-			if frame.Function == "" || strings.HasPrefix(frame.Function, "testing.") {
-				hiddenFrame = true
-			}
-
-			// Also, skip any frames that are part of sweet itself based on the package
-			// name. We only skip any frames that are in the root sweet package and
-			// are not in a _test.go file so we still get file names when testing
-			// sweet itself.
-			if strings.HasPrefix(frame.Function, packageName+".") {
-				if !strings.HasSuffix(frame.File, "_test.go") {
-					hiddenFrame = true
-				}
+			if isStopFrame(frame) {
+				break
 			}
 
-			failFrames = append(failFrames, &failureFrame{
-				Filename:    frame.File,
-				LineNumber:  frame.Line,
-				HiddenFrame: hiddenFrame,
-			})
+			failFrames = append(failFrames, newFailureFrame(frame))
 
 			if !more {
 				break
@@ -110,6 +192,87 @@ func failTest(message string, callerSkip ...int) {
 	panic(failure)
 }
 
+func newFailureFrame(frame runtime.Frame) *failureFrame {
+	return &failureFrame{
+		Filename:    frame.File,
+		LineNumber:  frame.Line,
+		Function:    frame.Function,
+		HiddenFrame: isHiddenFrame(frame),
+	}
+}
+
+func isHiddenFrame(frame runtime.Frame) bool {
+	// Skip any frames that don't actually have a function name... cuz wtf
+	// is that anyway? Seems like they're runtime package functions.
+	if frame.Function == "" {
+		return true
+	}
+
+	// Skip anything from the standard library, such as the testing package
+	// or the sort package calling back into the test.
+	if isGoFrame(frame) {
+		return true
+	}
+
+	pkg := funcPackage(frame.Function)
+
+	// Also, skip any frames that are part of sweet itself based on the package
+	// name. We only skip any frames that are in the root sweet package and
+	// are not in a _test.go file so we still get file names when testing
+	// sweet itself.
+	if pkg == packageName {
+		return !strings.HasSuffix(frame.File, "_test.go")
+	}
+
+	return isHiddenPackage(pkg)
+}
+
+// panicFailure converts a recovered panic that didn't come from sweet into a
+// test failure. It needs to be called from the deferred function doing the
+// recovering so the stack of the panicking goroutine is still available.
+func panicFailure(value interface{}) *testFailed {
+	callers := make([]uintptr, 100)
+	callers = callers[:runtime.Callers(2, callers)]
+
+	stack := make([]runtime.Frame, 0, len(callers))
+	frames := runtime.CallersFrames(callers)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			break
+		}
+	}
+
+	// Everything up to and including the runtime's panic handling is part of
+	// the recovery and not the test, so start at the frame that panicked.
+	start := 0
+	for idx, frame := range stack {
+		if frame.Function == "runtime.gopanic" {
+			start = idx + 1
+		}
+	}
+	for start < len(stack) && strings.HasPrefix(stack[start].Function, "runtime.") {
+		start++
+	}
+
+	failFrames := make([]*failureFrame, 0)
+	for _, frame := range stack[start:] {
+		if isStopFrame(frame) {
+			break
+		}
+		failFrames = append(failFrames, newFailureFrame(frame))
+	}
+
+	return &testFailed{
+		Message:    fmt.Sprintf("panic: %v", value),
+		Frames:     failFrames,
+		Panicked:   true,
+		PanicValue: value,
+		Stack:      string(debug.Stack()),
+	}
+}
+
 // GomegaFail is a utility function provided to hook into the Gomega matcher library. To use
 // this it's easiest to do the following in your set up:
 //   func TestMain(m *testing.M) {
@@ -1,14 +1,88 @@
 package sweet
 
 import (
+	"path/filepath"
+	"runtime"
+
 	. "github.com/onsi/gomega"
 )
 
 type FailureSuite struct{}
 
-func (s *FailureSuite) TestIsGoPackage(t T) {
-	Expect(isGoPackage("/usr/lib/go/src/runtime/asm_amd64.s")).To(BeTrue())
-	Expect(isGoPackage("/usr/lib/go/src/reflect/value.go")).To(BeTrue())
+func (s *FailureSuite) TestIsGoRootFile(t T) {
+	goRoot := runtime.GOROOT()
+	Expect(isGoRootFile(filepath.Join(goRoot, "src", "runtime", "asm_amd64.s"))).To(BeTrue())
+	Expect(isGoRootFile(filepath.Join(goRoot, "src", "reflect", "value.go"))).To(BeTrue())
+
+	Expect(isGoRootFile("/home/aphistic/go/src/github.com/aphistic/sweet/failtests/failtests_test.go")).To(BeFalse())
+	Expect(isGoRootFile("/home/aphistic/go/pkg/mod/github.com/onsi/gomega@v1.5.0/gomega_dsl.go")).To(BeFalse())
+	Expect(isGoRootFile("")).To(BeFalse())
+}
+
+func (s *FailureSuite) TestFuncPackage(t T) {
+	Expect(funcPackage("runtime.gopanic")).To(Equal("runtime"))
+	Expect(funcPackage("reflect.Value.call")).To(Equal("reflect"))
+	Expect(funcPackage("net/http.(*Client).Do")).To(Equal("net/http"))
+	Expect(funcPackage("github.com/onsi/gomega/internal/assertion.(*Assertion).To")).
+		To(Equal("github.com/onsi/gomega/internal/assertion"))
+	Expect(funcPackage("github.com/aphistic/sweet.(*sweetT).Run.func1")).
+		To(Equal("github.com/aphistic/sweet"))
+	Expect(funcPackage("example.com/app/vendor/github.com/onsi/gomega.Expect")).
+		To(Equal("github.com/onsi/gomega"))
+}
+
+func (s *FailureSuite) TestIsStdPackage(t T) {
+	Expect(isStdPackage("runtime")).To(BeTrue())
+	Expect(isStdPackage("net/http")).To(BeTrue())
+
+	Expect(isStdPackage("github.com/aphistic/sweet")).To(BeFalse())
+	Expect(isStdPackage("main")).To(BeFalse())
+	Expect(isStdPackage("")).To(BeFalse())
+}
+
+func (s *FailureSuite) TestIsGoFrame(t T) {
+	goRoot := runtime.GOROOT()
+	Expect(isGoFrame(runtime.Frame{
+		File:     filepath.Join(goRoot, "src", "testing", "testing.go"),
+		Function: "testing.tRunner",
+	})).To(BeTrue())
+
+	// User code under a src directory isn't the standard library
+	Expect(isGoFrame(runtime.Frame{
+		File:     "/home/aphistic/go/src/runtime/mine.go",
+		Function: "github.com/aphistic/runtime.Thing",
+	})).To(BeFalse())
+
+	// Binaries built with -trimpath have relative file names
+	Expect(isGoFrame(runtime.Frame{
+		File:     "reflect/value.go",
+		Function: "reflect.Value.call",
+	})).To(BeTrue())
+	Expect(isGoFrame(runtime.Frame{
+		File:     "github.com/onsi/gomega@v1.5.0/gomega_dsl.go",
+		Function: "github.com/onsi/gomega.Expect",
+	})).To(BeFalse())
+}
+
+func (s *FailureSuite) TestIsHiddenFrame(t T) {
+	Expect(isHiddenFrame(runtime.Frame{
+		File:     "/home/aphistic/go/pkg/mod/github.com/onsi/gomega@v1.5.0/internal/assertion/assertion.go",
+		Function: "github.com/onsi/gomega/internal/assertion.(*Assertion).To",
+	})).To(BeTrue())
+	Expect(isHiddenFrame(runtime.Frame{
+		File:     "/home/aphistic/go/src/github.com/aphistic/sweet/t.go",
+		Function: "github.com/aphistic/sweet.(*sweetT).Fatal",
+	})).To(BeTrue())
+	Expect(isHiddenFrame(runtime.Frame{
+		File:     "/home/aphistic/go/src/github.com/aphistic/sweet/failtests/failtests_test.go",
+		Function: "github.com/aphistic/sweet/failtests.(*FailSuite).TestFails",
+	})).To(BeFalse())
+	Expect(isHiddenFrame(runtime.Frame{})).To(BeTrue())
+}
 
-	Expect(isGoPackage("//home/aphistic/go/src/github.com/aphistic/sweet/failtests/failtests_test.go")).To(Not(BeTrue()))
+func (s *FailureSuite) TestMatchesPackage(t T) {
+	packages := []string{"github.com/me/asserts"}
+	Expect(matchesPackage("github.com/me/asserts", packages)).To(BeTrue())
+	Expect(matchesPackage("github.com/me/asserts/internal", packages)).To(BeTrue())
+	Expect(matchesPackage("github.com/me/assertsmore", packages)).To(BeFalse())
 }
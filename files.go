@@ -0,0 +1,102 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	fileLockTimeout = 30 * time.Second
+	fileLockStale   = 2 * time.Minute
+	fileLockRetry   = 50 * time.Millisecond
+)
+
+// withFileLock calls f while holding a lock on path so multiple test binaries,
+// such as the ones for each package run by "go test ./...", can safely update
+// the same file. The lock is a separate file next to path.
+func withFileLock(path string, f func() error) error {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(fileLockTimeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			lockFile.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+
+		// If a test binary was killed while holding the lock it'll never be
+		// released, so don't let an old lock block us forever.
+		if fi, statErr := os.Stat(lockPath); statErr == nil && time.Since(fi.ModTime()) > fileLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(fileLockRetry)
+	}
+	defer os.Remove(lockPath)
+
+	return f()
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// openOutput opens the file at path for a reporter to write to, replacing it
+// if it exists. A path of "-" writes to stdout, in which case the returned
+// closer is nil.
+func openOutput(path string) (io.Writer, io.Closer, error) {
+	if path == "-" || path == "" {
+		return os.Stdout, nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f, nil
+}
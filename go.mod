@@ -3,9 +3,11 @@ module github.com/aphistic/sweet
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/mattn/go-colorable v0.1.1 // indirect
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b
 	github.com/onsi/gomega v1.5.0
 	github.com/sergi/go-diff v1.0.0
 	golang.org/x/crypto v0.0.0-20190426145343-a29dc8fdc734
+	gopkg.in/yaml.v2 v2.2.1
 )
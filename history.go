@@ -0,0 +1,435 @@
+package sweet
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The outcomes a test can have in the history.
+const (
+	HistoryPassed  = "pass"
+	HistoryFailed  = "fail"
+	HistorySkipped = "skip"
+)
+
+// HistoryRun is a single run of a package's tests saved in the history.
+// Commit and Source identify the code that was run, Source being a hash of the
+// package's Go source so runs of the same commit with different local changes
+// can be told apart.
+type HistoryRun struct {
+	Time     time.Time
+	Package  string
+	Commit   string `json:",omitempty"`
+	Source   string `json:",omitempty"`
+	Seed     int64  `json:",omitempty"`
+	Duration time.Duration
+	Results  []*HistoryResult
+}
+
+// HistoryResult is the result of a single test in a run. Test is the full
+// name of the test, such as "MySuite/TestThing/SubTest".
+type HistoryResult struct {
+	Test     string
+	Outcome  string
+	Duration time.Duration
+}
+
+// History is the saved results of a package's previous runs, oldest first.
+type History struct {
+	Runs []*HistoryRun
+}
+
+// LoadHistory loads the history of the package with the given import path
+// from the history directory. A package that hasn't been run yet has an empty
+// history.
+func LoadHistory(dir string, pkg string) (*History, error) {
+	f, err := os.Open(historyFile(dir, pkg))
+	if os.IsNotExist(err) {
+		return &History{Runs: make([]*HistoryRun, 0)}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	history := &History{Runs: make([]*HistoryRun, 0)}
+
+	reader := bufio.NewReader(f)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			run := &HistoryRun{}
+			if jsonErr := json.Unmarshal(line, run); jsonErr != nil {
+				return nil, fmt.Errorf("could not read run on line %d of %s: %s", lineNum, f.Name(), jsonErr)
+			}
+			history.Runs = append(history.Runs, run)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return history, nil
+}
+
+// Last returns the history of the most recent runs, up to count of them.
+func (h *History) Last(count int) *History {
+	if count <= 0 || count >= len(h.Runs) {
+		return h
+	}
+
+	return &History{Runs: h.Runs[len(h.Runs)-count:]}
+}
+
+// Tests returns the names of every test in the history, sorted by name.
+func (h *History) Tests() []string {
+	names := make(map[string]bool)
+	for _, run := range h.Runs {
+		for _, result := range run.Results {
+			names[result.Test] = true
+		}
+	}
+
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// Results returns the results of a test in each run it was part of, oldest
+// first.
+func (h *History) Results(test string) []*HistoryResult {
+	res := make([]*HistoryResult, 0)
+	for _, run := range h.Runs {
+		for _, result := range run.Results {
+			if result.Test == test {
+				res = append(res, result)
+			}
+		}
+	}
+
+	return res
+}
+
+// FailureRate returns the fraction of the runs of a test, from 0 to 1, that
+// failed. Skipped runs aren't counted.
+func (h *History) FailureRate(test string) float64 {
+	runs, failures := 0, 0
+	for _, result := range h.Results(test) {
+		switch result.Outcome {
+		case HistoryPassed:
+			runs++
+		case HistoryFailed:
+			runs++
+			failures++
+		}
+	}
+	if runs == 0 {
+		return 0
+	}
+
+	return float64(failures) / float64(runs)
+}
+
+// AverageDuration returns the average time a test took when it passed.
+func (h *History) AverageDuration(test string) time.Duration {
+	var total time.Duration
+	count := 0
+	for _, result := range h.Results(test) {
+		if result.Outcome == HistoryPassed {
+			total += result.Duration
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+
+	return total / time.Duration(count)
+}
+
+// Flaky returns the tests that both passed and failed in runs of the same
+// commit and source, sorted by name. Runs without a commit or source hash
+// aren't used since there's no way to know whether the code changed between
+// them.
+func (h *History) Flaky() []string {
+	type outcomes struct {
+		passed bool
+		failed bool
+	}
+	seen := make(map[string]map[string]*outcomes)
+
+	for _, run := range h.Runs {
+		if run.Commit == "" || run.Source == "" {
+			continue
+		}
+		version := run.Commit + "/" + run.Source
+		for _, result := range run.Results {
+			versions, ok := seen[result.Test]
+			if !ok {
+				versions = make(map[string]*outcomes)
+				seen[result.Test] = versions
+			}
+			o, ok := versions[version]
+			if !ok {
+				o = &outcomes{}
+				versions[version] = o
+			}
+
+			switch result.Outcome {
+			case HistoryPassed:
+				o.passed = true
+			case HistoryFailed:
+				o.failed = true
+			}
+		}
+	}
+
+	res := make([]string, 0)
+	for test, versions := range seen {
+		for _, o := range versions {
+			if o.passed && o.failed {
+				res = append(res, test)
+				break
+			}
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func historyFile(dir string, pkg string) string {
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(pkg, "_")+".jsonl")
+}
+
+// appendHistory adds a run to the end of the package's history file.
+func appendHistory(dir string, run *HistoryRun) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	path := historyFile(dir, run.Package)
+	return withFileLock(path, func() error {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.Write(append(data, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	})
+}
+
+// historyRecorder is the plugin saving the results of each run when
+// -sweet.history is used.
+type historyRecorder struct {
+	BasePlugin
+
+	dir string
+
+	lock sync.Mutex
+	run  *HistoryRun
+}
+
+func newHistoryRecorder(dir string) *historyRecorder {
+	return &historyRecorder{
+		dir: dir,
+		run: &HistoryRun{
+			Package: packageImportPath(),
+			Results: make([]*HistoryResult, 0),
+		},
+	}
+}
+
+func (p *historyRecorder) Name() string {
+	return "History Recorder"
+}
+
+func (p *historyRecorder) Starting() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.run.Time = time.Now()
+	p.run.Seed = shuffleSeed()
+	if wd, err := os.Getwd(); err == nil {
+		p.run.Commit = gitCommit(findRepoRoot(wd))
+		if root := findModuleRoot(wd); root != "" {
+			p.run.Source = sourceHash(wd, root)
+		}
+	}
+}
+
+func (p *historyRecorder) Finished() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.run.Duration = time.Since(p.run.Time)
+
+	err := appendHistory(p.dir, p.run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not save the test history to %s: %s\n", p.dir, err)
+	}
+}
+
+func (p *historyRecorder) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.addResult(testName, HistoryPassed, stats.Time)
+}
+func (p *historyRecorder) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.addResult(testName, HistoryFailed, stats.Time)
+}
+func (p *historyRecorder) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.addResult(testName, HistorySkipped, stats.Time)
+}
+
+func (p *historyRecorder) addResult(testName *TestName, outcome string, d time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.run.Results = append(p.run.Results, &HistoryResult{
+		Test:     testName.String(),
+		Outcome:  outcome,
+		Duration: d,
+	})
+}
+
+// shuffleSeed returns the seed provided with -test.shuffle, or 0 if tests
+// aren't being shuffled with a known seed.
+func shuffleSeed() int64 {
+	shuffleFlag := flag.Lookup("test.shuffle")
+	if shuffleFlag == nil {
+		return 0
+	}
+
+	seed, err := strconv.ParseInt(shuffleFlag.Value.String(), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return seed
+}
+
+// sourceHash returns a hash of the Go files in the package directory along
+// with the go.mod and go.sum of the module at root. Only the package being
+// tested is hashed so each package's test binary doesn't read the whole
+// module. It returns an empty string if any of the files can't be read.
+func sourceHash(pkgDir string, root string) string {
+	files, err := ioutil.ReadDir(pkgDir)
+	if err != nil {
+		return ""
+	}
+
+	paths := make([]string, 0, len(files)+2)
+	for _, fi := range files {
+		if fi.Mode().IsRegular() && strings.HasSuffix(fi.Name(), ".go") {
+			paths = append(paths, filepath.Join(pkgDir, fi.Name()))
+		}
+	}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		path := filepath.Join(root, name)
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	// ReadDir sorts the files by name so the hash is the same for the same
+	// source.
+	hash := sha1.New()
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.Base(path), len(data))
+		hash.Write(data)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// gitCommit returns the commit checked out in the git repository at root,
+// reading the repository's files directly so git doesn't need to be
+// installed. It returns an empty string if the commit can't be found.
+func gitCommit(root string) string {
+	if root == "" {
+		return ""
+	}
+
+	gitDir := filepath.Join(root, ".git")
+	if fi, err := os.Stat(gitDir); err == nil && !fi.IsDir() {
+		// Worktrees and submodules have a file pointing at the git directory
+		data, err := ioutil.ReadFile(gitDir)
+		if err != nil {
+			return ""
+		}
+		linked := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(linked) {
+			linked = filepath.Join(root, linked)
+		}
+		gitDir = linked
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, "ref:") {
+		// A detached head has the commit itself
+		return ref
+	}
+	ref = strings.TrimSpace(strings.TrimPrefix(ref, "ref:"))
+
+	// Linked worktrees keep their refs in the main repository
+	refDirs := []string{gitDir}
+	if common, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		refDirs = append(refDirs, commonDir)
+	}
+
+	for _, dir := range refDirs {
+		if commit, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(commit))
+		}
+
+		packed, err := ioutil.ReadFile(filepath.Join(dir, "packed-refs"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(packed), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[1] == ref {
+				return fields[0]
+			}
+		}
+	}
+
+	return ""
+}
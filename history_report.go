@@ -0,0 +1,221 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func init() {
+	RegisterReporter("history", func() Plugin { return NewHistoryReporter() })
+}
+
+// historyReporter compares the results of a run with the history saved with
+// -sweet.history and lists the tests that have become slower or fail too
+// often.
+type historyReporter struct {
+	BasePlugin
+
+	out io.Writer
+	dir string
+	pkg string
+
+	runs        int
+	failRate    int
+	slowdown    int
+	minDuration time.Duration
+
+	previous *History
+
+	lock    sync.Mutex
+	current *HistoryRun
+}
+
+// NewHistoryReporter creates a reporter listing the tests that have become
+// slower or fail often according to the test history.
+func NewHistoryReporter() Plugin {
+	return newHistoryReporter(os.Stdout, *flagHistory)
+}
+
+func newHistoryReporter(out io.Writer, dir string) *historyReporter {
+	pkg := packageImportPath()
+
+	return &historyReporter{
+		out: out,
+		dir: dir,
+		pkg: pkg,
+
+		runs:        10,
+		failRate:    20,
+		slowdown:    50,
+		minDuration: 10 * time.Millisecond,
+
+		current: &HistoryRun{
+			Package: pkg,
+			Results: make([]*HistoryResult, 0),
+		},
+	}
+}
+
+func (p *historyReporter) Name() string {
+	return "History Reporter"
+}
+
+func (p *historyReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "history",
+		Options: map[string]*PluginOption{
+			"runs": {
+				Help:    "Number of the most recent runs to look at",
+				Default: "10",
+				Type:    OptionInt,
+			},
+			"failrate": {
+				Help:    "Percentage of runs a test can fail in before it's listed",
+				Default: "20",
+				Type:    OptionInt,
+			},
+			"slowdown": {
+				Help:    "Percentage a test can be slower than its average before it's listed",
+				Default: "50",
+				Type:    OptionInt,
+			},
+			"minduration": {
+				Help:    "Tests faster than this aren't listed as slower",
+				Default: "10ms",
+				Type:    OptionDuration,
+			},
+		},
+	}
+}
+
+func (p *historyReporter) SetOption(name, value string) {
+	// The values have already been validated
+	switch name {
+	case "runs":
+		p.runs, _ = strconv.Atoi(value)
+	case "failrate":
+		p.failRate, _ = strconv.Atoi(value)
+	case "slowdown":
+		p.slowdown, _ = strconv.Atoi(value)
+	case "minduration":
+		p.minDuration, _ = time.ParseDuration(value)
+	}
+}
+
+func (p *historyReporter) Starting() {
+	if p.dir == "" {
+		fmt.Fprintf(os.Stderr, "ERROR: The history reporter needs a history directory set with -sweet.history\n")
+		return
+	}
+
+	// Load the history before this run is added to it
+	history, err := LoadHistory(p.dir, p.pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not load the test history from %s: %s\n", p.dir, err)
+		return
+	}
+	p.previous = history.Last(p.runs)
+}
+
+func (p *historyReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.addResult(testName, HistoryPassed, stats.Time)
+}
+func (p *historyReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.addResult(testName, HistoryFailed, stats.Time)
+}
+func (p *historyReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.addResult(testName, HistorySkipped, stats.Time)
+}
+
+func (p *historyReporter) addResult(testName *TestName, outcome string, d time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.current.Results = append(p.current.Results, &HistoryResult{
+		Test:     testName.String(),
+		Outcome:  outcome,
+		Duration: d,
+	})
+}
+
+func (p *historyReporter) Finished() {
+	if p.previous == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	lines := p.report()
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(p.out, "History Report (last %d runs):\n", p.runs)
+	fmt.Fprintf(p.out, "------------------------------\n")
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+	fmt.Fprintln(p.out, "")
+}
+
+// report returns a line for every test that's slower than usual or failing
+// more often than allowed.
+func (p *historyReporter) report() []string {
+	lines := make([]string, 0)
+
+	for _, result := range p.current.Results {
+		if result.Outcome != HistoryPassed || result.Duration < p.minDuration {
+			continue
+		}
+
+		average := p.previous.AverageDuration(result.Test)
+		if average <= 0 {
+			continue
+		}
+
+		slowdown := float64(result.Duration-average) / float64(average) * 100
+		if slowdown > float64(p.slowdown) {
+			lines = append(lines, fmt.Sprintf(
+				"Slower: %s took %s, %.0f%% slower than its average of %s",
+				result.Test,
+				result.Duration.Round(time.Millisecond),
+				slowdown,
+				average.Round(time.Millisecond),
+			))
+		}
+	}
+
+	// The failure rate includes this run
+	runs := make([]*HistoryRun, 0, len(p.previous.Runs)+1)
+	runs = append(runs, p.previous.Runs...)
+	runs = append(runs, p.current)
+	recent := (&History{Runs: runs}).Last(p.runs)
+
+	for _, test := range recent.Tests() {
+		rate := recent.FailureRate(test)
+		if rate*100 <= float64(p.failRate) {
+			continue
+		}
+
+		failures, total := 0, 0
+		for _, result := range recent.Results(test) {
+			if result.Outcome == HistoryFailed {
+				failures++
+			}
+			if result.Outcome != HistorySkipped {
+				total++
+			}
+		}
+		lines = append(lines, fmt.Sprintf(
+			"Failing: %s failed in %d of %d runs (%.0f%%)",
+			test, failures, total, rate*100,
+		))
+	}
+
+	return lines
+}
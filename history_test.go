@@ -0,0 +1,218 @@
+package sweet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type HistorySuite struct{}
+
+func (s *HistorySuite) tempDir() string {
+	dir, err := ioutil.TempDir("", "sweet-history")
+	Expect(err).To(BeNil())
+	return dir
+}
+
+func (s *HistorySuite) TestRecord(t T) {
+	dir := s.tempDir()
+	defer os.RemoveAll(dir)
+
+	passName := newTestName("MySuite", []string{"TestPass"})
+	failName := newTestName("MySuite", []string{"TestFail"})
+
+	for idx := 0; idx < 2; idx++ {
+		p := newHistoryRecorder(dir)
+		p.run.Package = "example.com/pkg"
+		p.Starting()
+		p.TestPassed(passName, &TestPassedStats{Time: time.Second})
+		p.TestFailed(failName, &TestFailedStats{Time: 2 * time.Second})
+		p.Finished()
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "example.com_pkg.jsonl"))
+	Expect(err).To(BeNil())
+
+	history, err := LoadHistory(dir, "example.com/pkg")
+	Expect(err).To(BeNil())
+	Expect(history.Runs).To(HaveLen(2))
+	Expect(history.Runs[0].Package).To(Equal("example.com/pkg"))
+	Expect(history.Runs[0].Results).To(Equal([]*HistoryResult{
+		{Test: "MySuite/TestPass", Outcome: HistoryPassed, Duration: time.Second},
+		{Test: "MySuite/TestFail", Outcome: HistoryFailed, Duration: 2 * time.Second},
+	}))
+
+	history, err = LoadHistory(dir, "example.com/other")
+	Expect(err).To(BeNil())
+	Expect(history.Runs).To(BeEmpty())
+}
+
+func (s *HistorySuite) TestQueries(t T) {
+	result := func(test, outcome string, d time.Duration) *HistoryResult {
+		return &HistoryResult{Test: test, Outcome: outcome, Duration: d}
+	}
+
+	history := &History{Runs: []*HistoryRun{
+		{Results: []*HistoryResult{
+			result("S/TestA", HistoryPassed, time.Second),
+			result("S/TestB", HistoryFailed, time.Second),
+		}},
+		{Results: []*HistoryResult{
+			result("S/TestA", HistoryPassed, 3*time.Second),
+			result("S/TestB", HistorySkipped, time.Second),
+		}},
+		{Results: []*HistoryResult{
+			result("S/TestA", HistoryFailed, 10*time.Second),
+			result("S/TestB", HistoryPassed, time.Second),
+			result("S/TestC", HistoryPassed, time.Second),
+		}},
+	}}
+
+	Expect(history.Tests()).To(Equal([]string{"S/TestA", "S/TestB", "S/TestC"}))
+	Expect(history.Results("S/TestA")).To(HaveLen(3))
+	Expect(history.FailureRate("S/TestA")).To(BeNumerically("~", 1.0/3))
+	Expect(history.FailureRate("S/TestB")).To(Equal(0.5))
+	Expect(history.FailureRate("S/TestD")).To(Equal(0.0))
+	Expect(history.AverageDuration("S/TestA")).To(Equal(2 * time.Second))
+
+	Expect(history.Last(1).Runs).To(HaveLen(1))
+	Expect(history.Last(1).Tests()).To(Equal([]string{"S/TestA", "S/TestB", "S/TestC"}))
+	Expect(history.Last(5).Runs).To(HaveLen(3))
+}
+
+func (s *HistorySuite) TestReporter(t T) {
+	dir := s.tempDir()
+	defer os.RemoveAll(dir)
+
+	for idx := 0; idx < 4; idx++ {
+		flakyOutcome := HistoryPassed
+		if idx%2 == 0 {
+			flakyOutcome = HistoryFailed
+		}
+		Expect(appendHistory(dir, &HistoryRun{
+			Package: "example.com/pkg",
+			Results: []*HistoryResult{
+				{Test: "S/TestSlower", Outcome: HistoryPassed, Duration: 100 * time.Millisecond},
+				{Test: "S/TestSteady", Outcome: HistoryPassed, Duration: 100 * time.Millisecond},
+				{Test: "S/TestFlaky", Outcome: flakyOutcome, Duration: time.Millisecond},
+			},
+		})).To(Succeed())
+	}
+
+	out := &bytes.Buffer{}
+	p := newHistoryReporter(out, dir)
+	p.pkg = "example.com/pkg"
+	p.SetOption("runs", "5")
+
+	p.Starting()
+	p.TestPassed(newTestName("S", []string{"TestSlower"}), &TestPassedStats{Time: 300 * time.Millisecond})
+	p.TestPassed(newTestName("S", []string{"TestSteady"}), &TestPassedStats{Time: 120 * time.Millisecond})
+	p.TestPassed(newTestName("S", []string{"TestFlaky"}), &TestPassedStats{Time: time.Millisecond})
+	p.Finished()
+
+	Expect(out.String()).To(Equal("History Report (last 5 runs):\n" +
+		"------------------------------\n" +
+		"Slower: S/TestSlower took 300ms, 200% slower than its average of 100ms\n" +
+		"Failing: S/TestFlaky failed in 2 of 5 runs (40%)\n" +
+		"\n"))
+}
+
+func (s *HistorySuite) TestGitCommit(t T) {
+	root := s.tempDir()
+	defer os.RemoveAll(root)
+
+	gitDir := filepath.Join(root, ".git")
+	write := func(name, contents string) {
+		path := filepath.Join(gitDir, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	Expect(gitCommit("")).To(Equal(""))
+	Expect(gitCommit(root)).To(Equal(""))
+
+	write("HEAD", "ref: refs/heads/main\n")
+	write("packed-refs", "# pack-refs with: peeled\nabc123 refs/heads/main\n")
+	Expect(gitCommit(root)).To(Equal("abc123"))
+
+	write("refs/heads/main", "def456\n")
+	Expect(gitCommit(root)).To(Equal("def456"))
+
+	write("HEAD", "0123456789abcdef\n")
+	Expect(gitCommit(root)).To(Equal("0123456789abcdef"))
+
+	// A worktree has a .git file pointing at the real git directory
+	worktree := filepath.Join(root, "worktree")
+	Expect(os.MkdirAll(worktree, 0755)).To(Succeed())
+	Expect(ioutil.WriteFile(
+		filepath.Join(worktree, ".git"),
+		[]byte("gitdir: "+filepath.Join(gitDir, "worktrees", "wt")+"\n"),
+		0644,
+	)).To(Succeed())
+	write("worktrees/wt/HEAD", "ref: refs/heads/feature\n")
+	write("worktrees/wt/commondir", "../..\n")
+	write("refs/heads/feature", "fedcba\n")
+	Expect(gitCommit(worktree)).To(Equal("fedcba"))
+}
+
+func (s *HistorySuite) TestSourceHash(t T) {
+	root := s.tempDir()
+	defer os.RemoveAll(root)
+
+	write := func(name, contents string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	write("go.mod", "module example.com/module\n")
+	write("pkg/pkg.go", "package pkg\n")
+	pkgDir := filepath.Join(root, "pkg")
+	hash := sourceHash(pkgDir, root)
+	Expect(hash).ToNot(BeEmpty())
+
+	// Files that aren't source and other packages are left out
+	write("pkg/junit.xml", "<testsuites/>")
+	write("other/other.go", "package other\n")
+	write("pkg/sub/sub.go", "package sub\n")
+	Expect(sourceHash(pkgDir, root)).To(Equal(hash))
+
+	write("pkg/pkg.go", "package pkg\n\nvar changed = true\n")
+	changed := sourceHash(pkgDir, root)
+	Expect(changed).ToNot(Equal(hash))
+
+	write("go.sum", "example.com/dep v1.0.0 h1:abc=\n")
+	Expect(sourceHash(pkgDir, root)).ToNot(Equal(changed))
+}
+
+func (s *HistorySuite) TestFlaky(t T) {
+	run := func(commit string, source string, outcomes ...string) *HistoryRun {
+		res := &HistoryRun{Commit: commit, Source: source}
+		for idx, outcome := range outcomes {
+			res.Results = append(res.Results, &HistoryResult{
+				Test:    fmt.Sprintf("S/Test%d", idx),
+				Outcome: outcome,
+			})
+		}
+		return res
+	}
+
+	history := &History{Runs: []*HistoryRun{
+		run("abc", "111", HistoryPassed, HistoryPassed, HistoryFailed, HistoryPassed),
+		run("abc", "111", HistoryFailed, HistoryPassed, HistorySkipped, HistoryPassed),
+		// A test failing after the code changed isn't flaky
+		run("def", "111", HistoryPassed, HistoryFailed, HistoryPassed, HistoryPassed),
+		// Nor is one failing after local changes to the same commit
+		run("abc", "222", HistoryPassed, HistoryPassed, HistoryPassed, HistoryFailed),
+		// Runs without a commit or source can't be compared
+		run("", "111", HistoryPassed, HistoryPassed, HistoryFailed, HistoryFailed),
+		run("abc", "", HistoryPassed, HistoryFailed, HistoryPassed, HistoryPassed),
+	}}
+
+	Expect(history.Flaky()).To(Equal([]string{"S/Test0"}))
+}
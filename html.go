@@ -0,0 +1,362 @@
+package sweet
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterReporter("html", func() Plugin { return NewHTMLReporter() })
+}
+
+const (
+	htmlPassed  = "passed"
+	htmlFailed  = "failed"
+	htmlSkipped = "skipped"
+
+	htmlQuarantined = "quarantined"
+
+	// htmlSnippetContext is the number of lines shown around failures when
+	// -sweet.snippet wasn't used.
+	htmlSnippetContext = 3
+)
+
+// htmlPackage holds the results of the tests in a package. It's saved in the
+// report's data directory so the report can be generated from the results of
+// every package that has written to it.
+type htmlPackage struct {
+	Package  string
+	Started  time.Time
+	Duration time.Duration
+	Status   string
+	Suites   []*htmlSuite
+}
+
+type htmlSuite struct {
+	Name     string
+	Duration time.Duration
+	Status   string
+	Tests    []*htmlTest
+}
+
+type htmlTest struct {
+	Name     string
+	FullName string
+	Status   string
+	Duration time.Duration
+
+	Message  string        `json:",omitempty"`
+	DiffHTML template.HTML `json:",omitempty"`
+	Frames   []*htmlFrame  `json:",omitempty"`
+	Stack    string        `json:",omitempty"`
+	Output   []string      `json:",omitempty"`
+	Subtests []*htmlTest   `json:",omitempty"`
+}
+
+type htmlFrame struct {
+	File    string
+	Line    int
+	Snippet *SourceSnippet `json:",omitempty"`
+}
+
+// htmlReport is the data used to render the report.
+type htmlReport struct {
+	Generated time.Time
+	Packages  []*htmlPackage
+
+	Passed      int
+	Failed      int
+	Skipped     int
+	Quarantined int
+	Duration    time.Duration
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// htmlReporter writes the results to a single HTML file in the output
+// directory. Each package saves its results to the directory and regenerates
+// the HTML from everything there, so running "go test ./..." with the same
+// output directory produces one report for all the packages.
+type htmlReporter struct {
+	outputDir string
+
+	differ *differ
+
+	pkg    *htmlPackage
+	suites map[string]*htmlSuite
+	tests  map[string]*htmlTest
+}
+
+// NewHTMLReporter creates a reporter writing the test results to a static
+// HTML file.
+func NewHTMLReporter() Plugin {
+	return newHTMLReporter()
+}
+
+func newHTMLReporter() *htmlReporter {
+	return &htmlReporter{
+		outputDir: "sweet-report",
+
+		differ: newDiffer(),
+
+		pkg: &htmlPackage{
+			Package: packageImportPath(),
+			Status:  htmlPassed,
+			Suites:  make([]*htmlSuite, 0),
+		},
+		suites: make(map[string]*htmlSuite),
+		tests:  make(map[string]*htmlTest),
+	}
+}
+
+func (p *htmlReporter) Name() string {
+	return "HTML Reporter"
+}
+
+func (p *htmlReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "html",
+		Options: map[string]*PluginOption{
+			"output": {
+				Help:    "Directory to write the report to, shared directories are merged",
+				Default: "sweet-report",
+				Type:    OptionPath,
+			},
+		},
+	}
+}
+
+func (p *htmlReporter) SetOption(name, value string) {
+	switch name {
+	case "output":
+		p.outputDir = value
+	}
+}
+
+func (p *htmlReporter) Starting() {
+	p.pkg.Started = time.Now()
+}
+
+func (p *htmlReporter) Finished() {
+	p.pkg.Duration = time.Since(p.pkg.Started)
+	sort.Slice(p.pkg.Suites, func(i, j int) bool {
+		return p.pkg.Suites[i].Name < p.pkg.Suites[j].Name
+	})
+
+	err := p.write()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write HTML report to %s: %s\n", p.outputDir, err)
+	}
+}
+
+func (p *htmlReporter) SuiteStarting(suite string) {
+	p.getSuite(suite)
+}
+
+func (p *htmlReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	p.getSuite(suite).Duration = stats.Time
+}
+
+func (p *htmlReporter) TestStarting(testName *TestName) {
+	p.getTest(testName)
+}
+
+func (p *htmlReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.finishTest(testName, htmlPassed, stats.Time)
+}
+
+func (p *htmlReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	test := p.finishTest(testName, htmlSkipped, stats.Time)
+	test.Message = stats.Message
+}
+
+func (p *htmlReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.getSuite(testName.SuiteName).Status = htmlFailed
+	p.pkg.Status = htmlFailed
+
+	p.failTest(testName, htmlFailed, stats)
+}
+
+// TestQuarantined shows a failed test that's in the quarantine file with its
+// failure, but without failing its suite.
+func (p *htmlReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	p.failTest(testName, htmlQuarantined, stats)
+}
+
+func (p *htmlReporter) failTest(testName *TestName, status string, stats *TestFailedStats) {
+	test := p.finishTest(testName, status, stats.Time)
+
+	if onlySubtestsFailed(stats) {
+		return
+	}
+
+	test.Message = stats.Message
+	if diff, ok := p.differ.HTMLDiff(stats.Message); ok {
+		test.DiffHTML = template.HTML(diff)
+	}
+	if stats.Panicked {
+		test.Stack = stats.Stack
+	}
+	for _, frame := range stats.Frames {
+		if frame.Hidden {
+			continue
+		}
+
+		snippet := frame.Source
+		if snippet == nil {
+			snippet = loadSnippet(frame.File, frame.Line, htmlSnippetContext)
+		}
+		test.Frames = append(test.Frames, &htmlFrame{
+			File:    frame.File,
+			Line:    frame.Line,
+			Snippet: snippet,
+		})
+	}
+}
+
+func (p *htmlReporter) TestOutput(testName *TestName, output string) {
+	test := p.getTest(testName)
+	test.Output = append(test.Output, output)
+}
+
+func (p *htmlReporter) getSuite(name string) *htmlSuite {
+	suite, ok := p.suites[name]
+	if !ok {
+		suite = &htmlSuite{
+			Name:   name,
+			Status: htmlPassed,
+			Tests:  make([]*htmlTest, 0),
+		}
+		p.suites[name] = suite
+		p.pkg.Suites = append(p.pkg.Suites, suite)
+	}
+
+	return suite
+}
+
+// getTest returns the test for the given name, adding it to its suite or
+// parent test if it hasn't been seen yet.
+func (p *htmlReporter) getTest(testName *TestName) *htmlTest {
+	key := testName.String()
+	if test, ok := p.tests[key]; ok {
+		return test
+	}
+
+	test := &htmlTest{
+		Name:     testName.TestNames[len(testName.TestNames)-1],
+		FullName: key,
+	}
+	p.tests[key] = test
+
+	if testName.Parent != nil {
+		parent := p.getTest(testName.Parent)
+		parent.Subtests = append(parent.Subtests, test)
+	} else {
+		suite := p.getSuite(testName.SuiteName)
+		suite.Tests = append(suite.Tests, test)
+	}
+
+	return test
+}
+
+func (p *htmlReporter) finishTest(testName *TestName, status string, duration time.Duration) *htmlTest {
+	test := p.getTest(testName)
+	test.Status = status
+	test.Duration = duration
+
+	return test
+}
+
+func (p *htmlReporter) write() error {
+	dataDir := filepath.Join(p.outputDir, "data")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p.pkg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	indexPath := filepath.Join(p.outputDir, "index.html")
+	return withFileLock(indexPath, func() error {
+		dataFile := unsafeFileChars.ReplaceAllString(p.pkg.Package, "_") + ".json"
+		err := writeFileAtomic(filepath.Join(dataDir, dataFile), data)
+		if err != nil {
+			return err
+		}
+
+		report, err := loadHTMLReport(dataDir)
+		if err != nil {
+			return err
+		}
+
+		var out strings.Builder
+		err = htmlReportTemplate.Execute(&out, report)
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(indexPath, []byte(out.String()))
+	})
+}
+
+// loadHTMLReport reads the results of every package saved in dataDir.
+func loadHTMLReport(dataDir string) (*htmlReport, error) {
+	files, err := ioutil.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &htmlReport{
+		Generated: time.Now(),
+		Packages:  make([]*htmlPackage, 0, len(files)),
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dataDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		pkg := &htmlPackage{}
+		err = json.Unmarshal(data, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %s", file.Name(), err)
+		}
+		report.Packages = append(report.Packages, pkg)
+
+		report.Duration += pkg.Duration
+		for _, suite := range pkg.Suites {
+			for _, test := range suite.Tests {
+				switch test.Status {
+				case htmlPassed:
+					report.Passed++
+				case htmlFailed:
+					report.Failed++
+				case htmlSkipped:
+					report.Skipped++
+				case htmlQuarantined:
+					report.Quarantined++
+				}
+			}
+		}
+	}
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Package < report.Packages[j].Package
+	})
+
+	return report, nil
+}
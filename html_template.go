@@ -0,0 +1,142 @@
+package sweet
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": func(d time.Duration) string {
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	},
+	"timestamp": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+	"snippetLine": func(snippet *SourceSnippet, idx int) int {
+		return snippet.StartLine + idx
+	},
+}).Parse(htmlReportSource))
+
+const htmlReportSource = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Sweet Test Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #24292e; background: #f6f8fa; }
+header { background: #24292e; color: #fff; padding: 16px 24px; }
+header h1 { margin: 0 0 8px 0; font-size: 20px; }
+.totals span { margin-right: 16px; }
+.controls { padding: 12px 24px; background: #fff; border-bottom: 1px solid #e1e4e8; position: sticky; top: 0; }
+.controls input[type=search] { width: 320px; padding: 4px 8px; }
+.controls label { margin-left: 12px; }
+main { padding: 12px 24px; }
+details { margin: 2px 0 2px 16px; }
+details.package { margin-left: 0; background: #fff; border: 1px solid #e1e4e8; border-radius: 4px; padding: 4px 8px; margin-bottom: 8px; }
+summary { cursor: pointer; padding: 2px 0; }
+.name { font-family: SFMono-Regular, Consolas, monospace; }
+.time { color: #6a737d; font-size: 12px; margin-left: 8px; }
+.status { display: inline-block; width: 88px; font-size: 12px; font-weight: bold; text-transform: uppercase; }
+.passed > summary .status { color: #28a745; }
+.failed > summary .status { color: #cb2431; }
+.skipped > summary .status { color: #b08800; }
+.quarantined > summary .status { color: #6f42c1; }
+.body { margin-left: 16px; }
+pre { background: #f6f8fa; border: 1px solid #e1e4e8; padding: 8px; overflow-x: auto; font-size: 12px; }
+.snippet .current { background: #ffeef0; font-weight: bold; }
+.label { font-size: 12px; font-weight: bold; color: #586069; margin-top: 8px; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<header>
+<h1>Sweet Test Report</h1>
+<div class="totals">
+<span>Generated {{timestamp .Generated}}</span>
+<span>{{len .Packages}} package(s)</span>
+<span>{{.Passed}} passed</span>
+<span>{{.Failed}} failed</span>
+<span>{{.Skipped}} skipped</span>
+{{if .Quarantined}}<span>{{.Quarantined}} quarantined</span>{{end}}
+<span>{{duration .Duration}}</span>
+</div>
+</header>
+<div class="controls">
+<input type="search" id="search" placeholder="Search tests">
+<label><input type="checkbox" class="filter" value="passed" checked> Passed</label>
+<label><input type="checkbox" class="filter" value="failed" checked> Failed</label>
+<label><input type="checkbox" class="filter" value="skipped" checked> Skipped</label>
+<label><input type="checkbox" class="filter" value="quarantined" checked> Quarantined</label>
+</div>
+<main>
+{{range .Packages}}
+<details class="package {{.Status}}"{{if eq .Status "failed"}} open{{end}}>
+<summary><span class="status">{{.Status}}</span><span class="name">{{.Package}}</span><span class="time">{{duration .Duration}}</span></summary>
+{{range .Suites}}
+<details class="suite {{.Status}}"{{if eq .Status "failed"}} open{{end}}>
+<summary><span class="status">{{.Status}}</span><span class="name">{{.Name}}</span><span class="time">{{duration .Duration}}</span></summary>
+{{range .Tests}}{{template "test" .}}{{end}}
+</details>
+{{end}}
+</details>
+{{end}}
+</main>
+<script>
+(function() {
+	var search = document.getElementById("search");
+	var filters = document.querySelectorAll(".filter");
+
+	function update() {
+		var query = search.value.toLowerCase();
+		var statuses = {};
+		for (var i = 0; i < filters.length; i++) {
+			statuses[filters[i].value] = filters[i].checked;
+		}
+
+		// Go from the deepest tests up so a test stays visible when one of its
+		// subtests matches.
+		var tests = document.querySelectorAll("details.test");
+		for (var i = tests.length - 1; i >= 0; i--) {
+			var test = tests[i];
+			var visible = statuses[test.dataset.status] &&
+				test.dataset.name.toLowerCase().indexOf(query) >= 0;
+			if (!visible) {
+				visible = test.querySelector("details.test:not(.hidden)") !== null;
+			}
+			test.classList.toggle("hidden", !visible);
+		}
+
+		var groups = document.querySelectorAll("details.suite, details.package");
+		for (var i = groups.length - 1; i >= 0; i--) {
+			var group = groups[i];
+			group.classList.toggle("hidden", group.querySelector("details.test:not(.hidden)") === null);
+		}
+	}
+
+	search.addEventListener("input", update);
+	for (var i = 0; i < filters.length; i++) {
+		filters[i].addEventListener("change", update);
+	}
+})();
+</script>
+</body>
+</html>
+{{define "test"}}
+<details class="test {{.Status}}" data-name="{{.FullName}}" data-status="{{.Status}}"{{if eq .Status "failed"}} open{{end}}>
+<summary><span class="status">{{.Status}}</span><span class="name">{{.Name}}</span><span class="time">{{duration .Duration}}</span></summary>
+<div class="body">
+{{if .Message}}<div class="label">Message</div><pre>{{.Message}}</pre>{{end}}
+{{if .DiffHTML}}<div class="label">Diff</div><pre class="diff">{{.DiffHTML}}</pre>{{end}}
+{{range .Frames}}
+<div class="label">{{.File}}:{{.Line}}</div>
+{{if .Snippet}}{{$snippet := .Snippet}}<pre class="snippet">{{range $idx, $line := .Snippet.Lines}}{{$num := snippetLine $snippet $idx}}<span{{if eq $num $snippet.Line}} class="current"{{end}}>{{printf "%4d" $num}} | {{$line}}</span>
+{{end}}</pre>{{end}}
+{{end}}
+{{if .Stack}}<div class="label">Stack</div><pre>{{.Stack}}</pre>{{end}}
+{{if .Output}}<div class="label">Output</div><pre>{{range .Output}}{{.}}
+{{end}}</pre>{{end}}
+{{range .Subtests}}{{template "test" .}}{{end}}
+</div>
+</details>
+{{end}}`
@@ -0,0 +1,113 @@
+package sweet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type HTMLSuite struct{}
+
+func (s *HTMLSuite) runReporter(dir string, pkg string, sourceFile string) {
+	p := newHTMLReporter()
+	p.pkg.Package = pkg
+	p.SetOption("output", dir)
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	subName := newSubtestName(testName, "Sub")
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestStarting(testName)
+	p.TestOutput(testName, "<logged>")
+	p.TestStarting(subName)
+	p.TestFailed(subName, &TestFailedStats{
+		Name:    subName,
+		Time:    10 * time.Millisecond,
+		Message: "Expected\n    <string>: a\nto equal\n    <string>: b",
+		Frames: []*TestFailedFrame{
+			{File: sourceFile, Line: 3},
+		},
+	})
+	p.TestFailed(testName, &TestFailedStats{
+		Name:           testName,
+		Time:           20 * time.Millisecond,
+		FailedSubtests: []*TestName{subName},
+	})
+	p.TestStarting(skipName)
+	p.TestSkipped(skipName, &TestSkippedStats{Message: "not today"})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: time.Second})
+	p.Finished()
+}
+
+func (s *HTMLSuite) TestReport(t T) {
+	dir, err := ioutil.TempDir("", "sweet-html")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	sourceFile := filepath.Join(dir, "source.go")
+	Expect(ioutil.WriteFile(sourceFile, []byte("line 1\nline 2\nfailing line\nline 4\n"), 0644)).To(Succeed())
+
+	reportDir := filepath.Join(dir, "report")
+	s.runReporter(reportDir, "example.com/first", sourceFile)
+	s.runReporter(reportDir, "example.com/second", sourceFile)
+	// Running a package again replaces its previous results
+	s.runReporter(reportDir, "example.com/first", sourceFile)
+
+	dataFiles, err := ioutil.ReadDir(filepath.Join(reportDir, "data"))
+	Expect(err).To(BeNil())
+	Expect(dataFiles).To(HaveLen(2))
+
+	data, err := ioutil.ReadFile(filepath.Join(reportDir, "index.html"))
+	Expect(err).To(BeNil())
+	report := string(data)
+
+	Expect(report).To(ContainSubstring("<span>2 package(s)</span>"))
+	Expect(report).To(ContainSubstring("<span>2 failed</span>"))
+	Expect(report).To(ContainSubstring("<span>2 skipped</span>"))
+	Expect(report).To(ContainSubstring(`<span class="name">example.com/first</span>`))
+	Expect(report).To(ContainSubstring(`<span class="name">example.com/second</span>`))
+	Expect(report).To(ContainSubstring(`data-name="MySuite/TestThing/Sub"`))
+	Expect(report).To(ContainSubstring("&lt;logged&gt;"))
+	Expect(report).To(ContainSubstring("not today"))
+	Expect(report).To(ContainSubstring(`<del style="background:#ffe6e6;">a</del>`))
+	Expect(report).To(ContainSubstring(`<span class="current">   3 | failing line</span>`))
+
+	_, err = os.Stat(filepath.Join(reportDir, "index.html.lock"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func (s *HTMLSuite) TestQuarantined(t T) {
+	dir, err := ioutil.TempDir("", "sweet-html")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	p := newHTMLReporter()
+	p.pkg.Package = "example.com/pkg"
+	p.SetOption("output", dir)
+
+	testName := newTestName("MySuite", []string{"TestFlaky"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestQuarantined(testName, &TestFailedStats{
+		Name:        testName,
+		Message:     "boom",
+		Quarantined: true,
+	})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{})
+	p.Finished()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	Expect(err).To(BeNil())
+	report := string(data)
+
+	Expect(report).To(ContainSubstring("<span>0 failed</span>"))
+	Expect(report).To(ContainSubstring("<span>1 quarantined</span>"))
+	Expect(report).To(ContainSubstring(`<details class="suite passed">`))
+	Expect(report).To(ContainSubstring(`data-status="quarantined"`))
+}
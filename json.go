@@ -0,0 +1,453 @@
+package sweet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterReporter("json", func() Plugin { return NewJSONReporter() })
+}
+
+const (
+	jsonModeSweet     = "sweet"
+	jsonModeTest2JSON = "test2json"
+)
+
+// jsonEvent is a single line written by the JSON reporter in sweet mode.
+type jsonEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Suite   string `json:",omitempty"`
+	// Test is the name of the test within the suite, with subtest names
+	// separated by slashes like "TestThing/SubTest".
+	Test    string   `json:",omitempty"`
+	Parent  string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  string   `json:",omitempty"`
+	Message string   `json:",omitempty"`
+
+	Frames         []*jsonFrame `json:",omitempty"`
+	Diff           *jsonDiff    `json:",omitempty"`
+	FailedSubtests []string     `json:",omitempty"`
+	Panicked       bool         `json:",omitempty"`
+	Stack          string       `json:",omitempty"`
+	Quarantined    bool         `json:",omitempty"`
+
+	Tags []string `json:",omitempty"`
+}
+
+type jsonFrame struct {
+	File     string
+	Line     int
+	Function string `json:",omitempty"`
+	Hidden   bool   `json:",omitempty"`
+}
+
+type jsonDiff struct {
+	Actual   string
+	Expected string
+}
+
+// test2jsonEvent matches the events written by "go test -json". The field
+// order and encoding match cmd/test2json so tools reading its output can
+// read these too.
+type test2jsonEvent struct {
+	Time    *time.Time `json:",omitempty"`
+	Action  string
+	Package string   `json:",omitempty"`
+	Test    string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  *string  `json:",omitempty"`
+}
+
+// jsonReporter writes a JSON object for every event it receives, one per
+// line. In test2json mode the events are the ones "go test -json" would
+// write for the same tests, using "Suite/Test/SubTest" as the test names.
+type jsonReporter struct {
+	outputPath string
+	mode       string
+	pkg        string
+
+	out    io.Writer
+	closer io.Closer
+	start  time.Time
+	err    error
+
+	failed       bool
+	failedSuites map[string]bool
+}
+
+// NewJSONReporter creates a reporter writing an event stream of JSON objects.
+func NewJSONReporter() Plugin {
+	return newJSONReporter()
+}
+
+func newJSONReporter() *jsonReporter {
+	return &jsonReporter{
+		outputPath: "-",
+		mode:       jsonModeSweet,
+		pkg:        packageImportPath(),
+
+		failedSuites: make(map[string]bool),
+	}
+}
+
+func (p *jsonReporter) Name() string {
+	return "JSON Reporter"
+}
+
+func (p *jsonReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "json",
+		Options: map[string]*PluginOption{
+			"output": {
+				Help:    "Path of the file to write events to, or - for stdout",
+				Default: "-",
+				Type:    OptionString,
+			},
+			"mode": {
+				Help:    "Format of the events, test2json matches \"go test -json\"",
+				Default: jsonModeSweet,
+				Type:    OptionEnum,
+				Values:  []string{jsonModeSweet, jsonModeTest2JSON},
+			},
+		},
+	}
+}
+
+func (p *jsonReporter) SetOption(name, value string) {
+	switch name {
+	case "output":
+		p.outputPath = value
+	case "mode":
+		p.mode = value
+	}
+}
+
+func (p *jsonReporter) Starting() {
+	p.start = time.Now()
+
+	if p.out == nil {
+		p.open()
+	}
+
+	if p.mode == jsonModeSweet {
+		p.writeEvent(&jsonEvent{Action: "start"})
+	}
+}
+
+func (p *jsonReporter) Finished() {
+	elapsed := time.Since(p.start).Seconds()
+
+	if p.mode == jsonModeTest2JSON {
+		result := "PASS"
+		if p.failed {
+			result = "FAIL"
+		}
+		p.writeTest2JSON("output", "", nil, result+"\n")
+		p.writeTest2JSON(strings.ToLower(result), "", &elapsed, "")
+	} else {
+		p.writeEvent(&jsonEvent{Action: "finish", Elapsed: &elapsed})
+	}
+
+	if p.closer != nil {
+		err := p.closer.Close()
+		if err != nil && p.err == nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Could not write JSON events to %s: %s\n", p.outputPath, err)
+		}
+		p.closer = nil
+	}
+}
+
+func (p *jsonReporter) SuiteStarting(suite string) {
+	p.SuiteStartingWithStats(suite, &SuiteStartingStats{})
+}
+
+func (p *jsonReporter) SuiteStartingWithStats(suite string, stats *SuiteStartingStats) {
+	if p.mode == jsonModeTest2JSON {
+		p.writeTest2JSON("run", suite, nil, "")
+		p.writeTest2JSON("output", suite, nil, "=== RUN   "+suite+"\n")
+		return
+	}
+
+	p.writeEvent(&jsonEvent{Action: "suite-start", Suite: suite, Tags: stats.Tags})
+}
+
+func (p *jsonReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	elapsed := stats.Time.Seconds()
+
+	if p.mode == jsonModeTest2JSON {
+		// Sweet doesn't report whether a suite failed, so go by whether any
+		// of its tests failed.
+		p.writeTest2JSONResult(suite, 0, p.suiteFailed(suite), false, stats.Time, nil)
+		return
+	}
+
+	p.writeEvent(&jsonEvent{Action: "suite-finish", Suite: suite, Elapsed: &elapsed, Tags: stats.Tags})
+}
+
+func (p *jsonReporter) TestStarting(testName *TestName) {
+	p.started("test-start", testName)
+}
+func (p *jsonReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.passed("test-pass", testName, stats)
+}
+func (p *jsonReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.failedTest("test-fail", testName, stats)
+}
+func (p *jsonReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.skipped("test-skip", testName, stats)
+}
+
+func (p *jsonReporter) SubtestStarting(testName *TestName) {
+	p.started("subtest-start", testName)
+}
+func (p *jsonReporter) SubtestPassed(testName *TestName, stats *TestPassedStats) {
+	p.passed("subtest-pass", testName, stats)
+}
+func (p *jsonReporter) SubtestFailed(testName *TestName, stats *TestFailedStats) {
+	p.failedTest("subtest-fail", testName, stats)
+}
+func (p *jsonReporter) SubtestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.skipped("subtest-skip", testName, stats)
+}
+
+// TestQuarantined writes a failed test that's in the quarantine file as a
+// failure with Quarantined set, or as a skip in test2json mode so it doesn't
+// fail the run for tools reading the events.
+func (p *jsonReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	if testName.IsSubtest() {
+		p.failedTest("subtest-fail", testName, stats)
+	} else {
+		p.failedTest("test-fail", testName, stats)
+	}
+}
+
+func (p *jsonReporter) TestOutput(testName *TestName, output string) {
+	if p.mode == jsonModeTest2JSON {
+		p.writeTest2JSON("output", jsonTestPath(testName), nil, indentOutput(testDepth(testName), output))
+		return
+	}
+
+	event := p.testEvent("output", testName)
+	event.Output = output
+	p.writeEvent(event)
+}
+
+func (p *jsonReporter) started(action string, testName *TestName) {
+	if p.mode == jsonModeTest2JSON {
+		name := jsonTestPath(testName)
+		p.writeTest2JSON("run", name, nil, "")
+		p.writeTest2JSON("output", name, nil, "=== RUN   "+name+"\n")
+		return
+	}
+
+	p.writeEvent(p.testEvent(action, testName))
+}
+
+func (p *jsonReporter) passed(action string, testName *TestName, stats *TestPassedStats) {
+	if p.mode == jsonModeTest2JSON {
+		p.writeTest2JSONResult(jsonTestPath(testName), testDepth(testName), false, false, stats.Time, nil)
+		return
+	}
+
+	event := p.testEvent(action, testName)
+	event.Elapsed = jsonSeconds(stats.Time)
+	p.writeEvent(event)
+}
+
+func (p *jsonReporter) failedTest(action string, testName *TestName, stats *TestFailedStats) {
+	if !stats.Quarantined {
+		p.failed = true
+		p.markSuiteFailed(testName.SuiteName)
+	}
+
+	if p.mode == jsonModeTest2JSON {
+		var details []string
+		if stats.Quarantined {
+			details = append(details, "quarantined")
+		}
+		if !onlySubtestsFailed(stats) {
+			details = append(details, strings.Split(failureBody(stats), "\n")...)
+		}
+		p.writeTest2JSONResult(jsonTestPath(testName), testDepth(testName),
+			!stats.Quarantined, stats.Quarantined, stats.Time, details)
+		return
+	}
+
+	event := p.testEvent(action, testName)
+	event.Elapsed = jsonSeconds(stats.Time)
+	event.Message = stats.Message
+	event.Panicked = stats.Panicked
+	event.Stack = stats.Stack
+	event.Quarantined = stats.Quarantined
+	for _, frame := range stats.Frames {
+		event.Frames = append(event.Frames, &jsonFrame{
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+			Hidden:   frame.Hidden,
+		})
+	}
+	for _, subtest := range stats.FailedSubtests {
+		event.FailedSubtests = append(event.FailedSubtests, strings.Join(subtest.TestNames, "/"))
+	}
+	if actual, expected, ok := gomegaValues(stats.Message); ok {
+		event.Diff = &jsonDiff{
+			Actual:   actual,
+			Expected: expected,
+		}
+	}
+	p.writeEvent(event)
+}
+
+func (p *jsonReporter) skipped(action string, testName *TestName, stats *TestSkippedStats) {
+	if p.mode == jsonModeTest2JSON {
+		var details []string
+		if stats.Message != "" {
+			details = strings.Split(stats.Message, "\n")
+		}
+		p.writeTest2JSONResult(jsonTestPath(testName), testDepth(testName), false, true, stats.Time, details)
+		return
+	}
+
+	event := p.testEvent(action, testName)
+	event.Elapsed = jsonSeconds(stats.Time)
+	event.Message = stats.Message
+	p.writeEvent(event)
+}
+
+func (p *jsonReporter) testEvent(action string, testName *TestName) *jsonEvent {
+	event := &jsonEvent{
+		Action: action,
+		Suite:  testName.SuiteName,
+		Test:   strings.Join(testName.TestNames, "/"),
+		Tags:   testName.Tags,
+	}
+	if testName.Parent != nil {
+		event.Parent = strings.Join(testName.Parent.TestNames, "/")
+	}
+
+	return event
+}
+
+func (p *jsonReporter) markSuiteFailed(suite string) {
+	p.failedSuites[suite] = true
+}
+
+func (p *jsonReporter) suiteFailed(suite string) bool {
+	return p.failedSuites[suite]
+}
+
+func (p *jsonReporter) writeTest2JSONResult(
+	name string,
+	depth int,
+	failed bool,
+	skipped bool,
+	elapsed time.Duration,
+	details []string,
+) {
+	result := "PASS"
+	action := "pass"
+	if failed {
+		result = "FAIL"
+		action = "fail"
+	} else if skipped {
+		result = "SKIP"
+		action = "skip"
+	}
+
+	// Round the same way test2json does when reading the time from the
+	// "--- PASS" line.
+	seconds, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", elapsed.Seconds()), 64)
+
+	indent := strings.Repeat("    ", depth)
+	p.writeTest2JSON("output", name, nil,
+		fmt.Sprintf("%s--- %s: %s (%.2fs)\n", indent, result, name, seconds))
+	for _, line := range details {
+		p.writeTest2JSON("output", name, nil, indent+"    "+line+"\n")
+	}
+	p.writeTest2JSON(action, name, &seconds, "")
+}
+
+func (p *jsonReporter) writeTest2JSON(action string, test string, elapsed *float64, output string) {
+	now := time.Now()
+	event := &test2jsonEvent{
+		Time:    &now,
+		Action:  action,
+		Package: p.pkg,
+		Test:    test,
+		Elapsed: elapsed,
+	}
+	if action == "output" {
+		event.Output = &output
+	}
+
+	p.write(event)
+}
+
+func (p *jsonReporter) writeEvent(event *jsonEvent) {
+	event.Time = time.Now()
+	event.Package = p.pkg
+
+	p.write(event)
+}
+
+func (p *jsonReporter) write(event interface{}) {
+	if p.err != nil {
+		return
+	}
+	if p.out == nil {
+		p.open()
+		if p.err != nil {
+			return
+		}
+	}
+
+	data, err := json.Marshal(event)
+	if err == nil {
+		_, err = p.out.Write(append(data, '\n'))
+	}
+	if err != nil {
+		p.err = err
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write JSON events to %s: %s\n", p.outputPath, err)
+	}
+}
+
+func (p *jsonReporter) open() {
+	p.out, p.closer, p.err = openOutput(p.outputPath)
+	if p.err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write JSON events to %s: %s\n", p.outputPath, p.err)
+	}
+}
+
+// jsonTestPath is the name "go test" would use for the test if each suite
+// was a top level test.
+func jsonTestPath(testName *TestName) string {
+	return testName.SuiteName + "/" + strings.Join(testName.TestNames, "/")
+}
+
+// testDepth is how deeply nested a test is below its suite, 1 for a test
+// method and more for each level of subtest.
+func testDepth(testName *TestName) int {
+	return len(testName.TestNames)
+}
+
+func indentOutput(depth int, output string) string {
+	indent := strings.Repeat("    ", depth+1)
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+	return indent + strings.Join(lines, "\n"+indent) + "\n"
+}
+
+func jsonSeconds(d time.Duration) *float64 {
+	seconds := d.Seconds()
+	return &seconds
+}
@@ -0,0 +1,192 @@
+package sweet
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type JSONSuite struct{}
+
+func (s *JSONSuite) runReporter(mode string) []map[string]interface{} {
+	out := &bytes.Buffer{}
+
+	p := newJSONReporter()
+	p.pkg = "example.com/pkg"
+	p.SetOption("mode", mode)
+	p.out = out
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	testName.Tags = []string{"integration"}
+	subName := newSubtestName(testName, "Sub")
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+
+	p.Starting()
+	p.SuiteStartingWithStats("MySuite", &SuiteStartingStats{Tags: []string{"e2e"}})
+	p.TestStarting(testName)
+	p.TestOutput(testName, "logged")
+	p.SubtestStarting(subName)
+	p.SubtestPassed(subName, &TestPassedStats{Time: 10 * time.Millisecond})
+	p.TestFailed(testName, &TestFailedStats{
+		Name:    testName,
+		Time:    1500 * time.Millisecond,
+		Message: "Expected\n    <int>: 1\nto equal\n    <int>: 2",
+		Frames: []*TestFailedFrame{
+			{File: "/src/my_test.go", Line: 20, Function: "MySuite.TestThing"},
+		},
+	})
+	p.TestStarting(skipName)
+	p.TestSkipped(skipName, &TestSkippedStats{Message: "not today"})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{
+		Time: 2 * time.Second,
+		Tags: []string{"e2e"},
+	})
+	p.Finished()
+
+	events := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		event := make(map[string]interface{})
+		Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+		Expect(event).To(HaveKey("Time"))
+		delete(event, "Time")
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func (s *JSONSuite) TestSweetMode(t T) {
+	events := s.runReporter(jsonModeSweet)
+
+	actions := make([]string, 0, len(events))
+	for _, event := range events {
+		Expect(event["Package"]).To(Equal("example.com/pkg"))
+		actions = append(actions, event["Action"].(string))
+	}
+	Expect(actions).To(Equal([]string{
+		"start",
+		"suite-start",
+		"test-start",
+		"output",
+		"subtest-start",
+		"subtest-pass",
+		"test-fail",
+		"test-start",
+		"test-skip",
+		"suite-finish",
+		"finish",
+	}))
+
+	Expect(events[1]["Tags"]).To(Equal([]interface{}{"e2e"}))
+	Expect(events[3]["Output"]).To(Equal("logged"))
+	Expect(events[5]["Test"]).To(Equal("TestThing/Sub"))
+	Expect(events[5]["Parent"]).To(Equal("TestThing"))
+	Expect(events[5]["Tags"]).To(Equal([]interface{}{"integration"}))
+
+	failed := events[6]
+	Expect(failed["Suite"]).To(Equal("MySuite"))
+	Expect(failed["Test"]).To(Equal("TestThing"))
+	Expect(failed["Elapsed"]).To(Equal(1.5))
+	Expect(failed["Frames"]).To(Equal([]interface{}{
+		map[string]interface{}{
+			"File":     "/src/my_test.go",
+			"Line":     20.0,
+			"Function": "MySuite.TestThing",
+		},
+	}))
+	Expect(failed["Diff"]).To(Equal(map[string]interface{}{
+		"Actual":   "    <int>: 1\n",
+		"Expected": "    <int>: 2\n",
+	}))
+
+	Expect(events[8]["Message"]).To(Equal("not today"))
+	Expect(events[8]).ToNot(HaveKey("Tags"))
+	Expect(events[9]["Tags"]).To(Equal([]interface{}{"e2e"}))
+}
+
+func (s *JSONSuite) TestTest2JSONMode(t T) {
+	events := s.runReporter(jsonModeTest2JSON)
+
+	// Leave out the elapsed time of the whole run since it's not fixed
+	Expect(events[len(events)-1]).To(HaveKey("Elapsed"))
+	delete(events[len(events)-1], "Elapsed")
+
+	event := func(action, test string, extra ...interface{}) map[string]interface{} {
+		res := map[string]interface{}{
+			"Action":  action,
+			"Package": "example.com/pkg",
+		}
+		if test != "" {
+			res["Test"] = test
+		}
+		for idx := 0; idx < len(extra); idx += 2 {
+			res[extra[idx].(string)] = extra[idx+1]
+		}
+		return res
+	}
+	output := func(test, output string) map[string]interface{} {
+		return event("output", test, "Output", output)
+	}
+
+	Expect(events).To(Equal([]map[string]interface{}{
+		event("run", "MySuite"),
+		output("MySuite", "=== RUN   MySuite\n"),
+		event("run", "MySuite/TestThing"),
+		output("MySuite/TestThing", "=== RUN   MySuite/TestThing\n"),
+		output("MySuite/TestThing", "        logged\n"),
+		event("run", "MySuite/TestThing/Sub"),
+		output("MySuite/TestThing/Sub", "=== RUN   MySuite/TestThing/Sub\n"),
+		output("MySuite/TestThing/Sub", "        --- PASS: MySuite/TestThing/Sub (0.01s)\n"),
+		event("pass", "MySuite/TestThing/Sub", "Elapsed", 0.01),
+		output("MySuite/TestThing", "    --- FAIL: MySuite/TestThing (1.50s)\n"),
+		output("MySuite/TestThing", "        /src/my_test.go:20\n"),
+		output("MySuite/TestThing", "        \n"),
+		output("MySuite/TestThing", "        Expected\n"),
+		output("MySuite/TestThing", "            <int>: 1\n"),
+		output("MySuite/TestThing", "        to equal\n"),
+		output("MySuite/TestThing", "            <int>: 2\n"),
+		event("fail", "MySuite/TestThing", "Elapsed", 1.5),
+		event("run", "MySuite/TestSkip"),
+		output("MySuite/TestSkip", "=== RUN   MySuite/TestSkip\n"),
+		output("MySuite/TestSkip", "    --- SKIP: MySuite/TestSkip (0.00s)\n"),
+		output("MySuite/TestSkip", "        not today\n"),
+		event("skip", "MySuite/TestSkip", "Elapsed", 0.0),
+		output("MySuite", "--- FAIL: MySuite (2.00s)\n"),
+		event("fail", "MySuite", "Elapsed", 2.0),
+		output("", "FAIL\n"),
+		event("fail", ""),
+	}))
+}
+
+func (s *JSONSuite) TestQuarantined(t T) {
+	for _, mode := range []string{jsonModeSweet, jsonModeTest2JSON} {
+		out := &bytes.Buffer{}
+
+		p := newJSONReporter()
+		p.pkg = "example.com/pkg"
+		p.SetOption("mode", mode)
+		p.out = out
+
+		testName := newTestName("MySuite", []string{"TestFlaky"})
+
+		p.Starting()
+		p.TestQuarantined(testName, &TestFailedStats{
+			Name:        testName,
+			Message:     "boom",
+			Quarantined: true,
+		})
+		p.Finished()
+
+		Expect(p.failed).To(BeFalse())
+		if mode == jsonModeSweet {
+			Expect(out.String()).To(ContainSubstring(`"Action":"test-fail"`))
+			Expect(out.String()).To(ContainSubstring(`"Quarantined":true`))
+		} else {
+			Expect(out.String()).To(ContainSubstring(`"Action":"skip","Package":"example.com/pkg","Test":"MySuite/TestFlaky"`))
+			Expect(out.String()).To(ContainSubstring(`"Action":"pass","Package":"example.com/pkg"`))
+		}
+	}
+}
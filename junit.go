@@ -0,0 +1,380 @@
+package sweet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterReporter("junit", func() Plugin { return NewJUnitReporter() })
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Package    string           `xml:"package,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	Hostname   string           `xml:"hostname,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Cases      []*junitTestCase `xml:"testcase"`
+
+	start time.Time
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+	Error      *junitFailure    `xml:"error,omitempty"`
+	Skipped    *junitSkipped    `xml:"skipped,omitempty"`
+	SystemOut  *junitOutput     `xml:"system-out,omitempty"`
+}
+
+// junitProperties holds a property for each of a test's or suite's tags.
+type junitProperties struct {
+	Properties []*junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func junitTagProperties(tags []string) *junitProperties {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	props := &junitProperties{}
+	for _, tag := range tags {
+		props.Properties = append(props.Properties, &junitProperty{Name: "tag", Value: tag})
+	}
+
+	return props
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type junitOutput struct {
+	Body string `xml:",chardata"`
+}
+
+// junitReporter writes the results of the tests to a JUnit XML file. When
+// several packages write to the same file each package's suites replace
+// the ones from its previous run while leaving the others alone.
+type junitReporter struct {
+	outputPath string
+	pkg        string
+	hostname   string
+
+	suites     []*junitTestSuite
+	testOutput map[string][]string
+}
+
+// NewJUnitReporter creates a reporter writing test results to a JUnit XML
+// file.
+func NewJUnitReporter() Plugin {
+	return newJUnitReporter()
+}
+
+func newJUnitReporter() *junitReporter {
+	hostname, _ := os.Hostname()
+
+	return &junitReporter{
+		outputPath: "junit.xml",
+		pkg:        packageImportPath(),
+		hostname:   hostname,
+
+		suites:     make([]*junitTestSuite, 0),
+		testOutput: make(map[string][]string),
+	}
+}
+
+func (p *junitReporter) Name() string {
+	return "JUnit Reporter"
+}
+
+func (p *junitReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "junit",
+		Options: map[string]*PluginOption{
+			"output": {
+				Help:    "Path of the JUnit XML file to write, relative to the module root, shared files are merged",
+				Default: "junit.xml",
+				Type:    OptionPath,
+			},
+		},
+	}
+}
+
+func (p *junitReporter) SetOption(name, value string) {
+	switch name {
+	case "output":
+		p.outputPath = value
+	}
+}
+
+func (p *junitReporter) Starting() {}
+
+func (p *junitReporter) SuiteStarting(suite string) {
+	p.SuiteStartingWithStats(suite, &SuiteStartingStats{})
+}
+
+func (p *junitReporter) SuiteStartingWithStats(suite string, stats *SuiteStartingStats) {
+	p.suites = append(p.suites, &junitTestSuite{
+		Name:       p.pkg + "/" + suite,
+		Package:    p.pkg,
+		Timestamp:  time.Now().UTC().Format("2006-01-02T15:04:05"),
+		Hostname:   p.hostname,
+		Properties: junitTagProperties(stats.Tags),
+		Cases:      make([]*junitTestCase, 0),
+		start:      time.Now(),
+	})
+}
+
+func (p *junitReporter) TestStarting(testName *TestName) {}
+
+func (p *junitReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.addCase(testName, stats.Time)
+}
+
+func (p *junitReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	tc := p.addCase(testName, stats.Time)
+	if onlySubtestsFailed(stats) {
+		names := make([]string, 0, len(stats.FailedSubtests))
+		for _, subtest := range stats.FailedSubtests {
+			names = append(names, subtest.String())
+		}
+		tc.Failure = &junitFailure{
+			Message: "Failed subtests: " + strings.Join(names, ", "),
+			Type:    "SubtestFailure",
+		}
+		return
+	}
+
+	failure := &junitFailure{
+		Message: firstLine(stats.Message),
+		Type:    "Failure",
+		Body:    failureBody(stats),
+	}
+	if stats.Panicked {
+		failure.Type = "Panic"
+		tc.Error = failure
+	} else {
+		tc.Failure = failure
+	}
+}
+
+// TestQuarantined writes a failed test that's in the quarantine file as a
+// skipped test case so it doesn't fail the report, with the failure in its
+// output.
+func (p *junitReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	tc := p.addCase(testName, stats.Time)
+	tc.Skipped = &junitSkipped{
+		Message: quarantinedMessage(stats),
+	}
+	if onlySubtestsFailed(stats) {
+		return
+	}
+
+	body := failureBody(stats)
+	if tc.SystemOut != nil {
+		body = tc.SystemOut.Body + "\n" + body
+	}
+	tc.SystemOut = &junitOutput{Body: body}
+}
+
+func (p *junitReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	tc := p.addCase(testName, stats.Time)
+	tc.Skipped = &junitSkipped{
+		Message: stats.Message,
+	}
+}
+
+func (p *junitReporter) TestOutput(testName *TestName, output string) {
+	key := testName.String()
+	p.testOutput[key] = append(p.testOutput[key], output)
+}
+
+func (p *junitReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	s := p.findSuite(suite)
+	if s == nil {
+		return
+	}
+	s.Time = junitSeconds(stats.Time)
+	s.Properties = junitTagProperties(stats.Tags)
+}
+
+func (p *junitReporter) Finished() {
+	err := p.write()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write JUnit report to %s: %s\n", p.outputPath, err)
+	}
+}
+
+func (p *junitReporter) findSuite(suite string) *junitTestSuite {
+	name := p.pkg + "/" + suite
+	for idx := len(p.suites) - 1; idx >= 0; idx-- {
+		if p.suites[idx].Name == name {
+			return p.suites[idx]
+		}
+	}
+
+	return nil
+}
+
+func (p *junitReporter) addCase(testName *TestName, testTime time.Duration) *junitTestCase {
+	s := p.findSuite(testName.SuiteName)
+	if s == nil {
+		p.SuiteStarting(testName.SuiteName)
+		s = p.findSuite(testName.SuiteName)
+	}
+
+	tc := &junitTestCase{
+		Name:       strings.Join(testName.TestNames, "/"),
+		ClassName:  p.pkg + "." + testName.SuiteName,
+		Time:       junitSeconds(testTime),
+		Properties: junitTagProperties(testName.Tags),
+	}
+	if output, ok := p.testOutput[testName.String()]; ok {
+		tc.SystemOut = &junitOutput{Body: strings.Join(output, "\n")}
+		delete(p.testOutput, testName.String())
+	}
+
+	s.Cases = append(s.Cases, tc)
+
+	return tc
+}
+
+func (p *junitReporter) write() error {
+	if err := os.MkdirAll(filepath.Dir(p.outputPath), 0755); err != nil {
+		return err
+	}
+
+	return withFileLock(p.outputPath, func() error {
+		report := &junitTestSuites{}
+
+		data, err := ioutil.ReadFile(p.outputPath)
+		if err == nil {
+			err = xml.Unmarshal(data, report)
+			if err != nil {
+				return fmt.Errorf("existing report could not be read: %s", err)
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		// Replace anything from a previous run of this package but keep the
+		// suites written by other packages.
+		suites := make([]*junitTestSuite, 0, len(report.Suites)+len(p.suites))
+		for _, suite := range report.Suites {
+			if suite.Package != p.pkg {
+				suites = append(suites, suite)
+			}
+		}
+		for _, suite := range p.suites {
+			suite.count()
+			suites = append(suites, suite)
+		}
+		report.Suites = suites
+		report.total()
+
+		data, err = xml.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(p.outputPath, append([]byte(xml.Header), append(data, '\n')...))
+	})
+}
+
+func (s *junitTestSuite) count() {
+	s.Tests, s.Failures, s.Errors, s.Skipped = 0, 0, 0, 0
+	for _, tc := range s.Cases {
+		s.Tests++
+		switch {
+		case tc.Error != nil:
+			s.Errors++
+		case tc.Failure != nil:
+			s.Failures++
+		case tc.Skipped != nil:
+			s.Skipped++
+		}
+	}
+	if s.Time == "" {
+		s.Time = junitSeconds(time.Since(s.start))
+	}
+}
+
+func (r *junitTestSuites) total() {
+	r.Tests, r.Failures, r.Errors, r.Skipped = 0, 0, 0, 0
+	totalTime := 0.0
+	for _, suite := range r.Suites {
+		r.Tests += suite.Tests
+		r.Failures += suite.Failures
+		r.Errors += suite.Errors
+		r.Skipped += suite.Skipped
+
+		var suiteTime float64
+		fmt.Sscanf(suite.Time, "%f", &suiteTime)
+		totalTime += suiteTime
+	}
+	r.Time = fmt.Sprintf("%.3f", totalTime)
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(message string) string {
+	if idx := strings.Index(message, "\n"); idx >= 0 {
+		return message[:idx]
+	}
+	return message
+}
+
+// failureBody is the plain text description of a failure used by reporters
+// writing to files, without any of the color the console uses.
+func failureBody(stats *TestFailedStats) string {
+	var body strings.Builder
+	for _, frame := range stats.Frames {
+		if !frame.Hidden {
+			fmt.Fprintf(&body, "%s:%d\n", frame.File, frame.Line)
+		}
+	}
+	if body.Len() > 0 {
+		body.WriteString("\n")
+	}
+	body.WriteString(stats.Message)
+
+	return body.String()
+}
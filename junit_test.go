@@ -0,0 +1,152 @@
+package sweet
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type JUnitSuite struct{}
+
+func (s *JUnitSuite) runReporter(path string, pkg string) {
+	p := newJUnitReporter()
+	p.pkg = pkg
+	p.SetOption("output", path)
+
+	passName := newTestName("MySuite", []string{"TestPass"})
+	subName := newSubtestName(passName, "Sub")
+	failName := newTestName("MySuite", []string{"TestFail"})
+	panicName := newTestName("MySuite", []string{"TestPanic"})
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+	skipName.Tags = []string{"slow", "flaky"}
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestStarting(passName)
+	p.TestOutput(passName, "logged")
+	p.TestStarting(subName)
+	p.TestPassed(subName, &TestPassedStats{Time: time.Second})
+	p.TestPassed(passName, &TestPassedStats{Time: 2 * time.Second})
+	p.TestFailed(failName, &TestFailedStats{
+		Name:    failName,
+		Message: "Expected\n    <bool>: false\nto be true",
+		Frames: []*TestFailedFrame{
+			{File: "/src/my_test.go", Line: 20},
+		},
+	})
+	p.TestFailed(panicName, &TestFailedStats{
+		Name:     panicName,
+		Message:  "panic: oh no",
+		Panicked: true,
+	})
+	p.TestSkipped(skipName, &TestSkippedStats{Message: "not today"})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{
+		Time: 3 * time.Second,
+		Tags: []string{"integration"},
+	})
+	p.Finished()
+}
+
+func (s *JUnitSuite) readReport(path string) *junitTestSuites {
+	data, err := ioutil.ReadFile(path)
+	Expect(err).To(BeNil())
+
+	report := &junitTestSuites{}
+	Expect(xml.Unmarshal(data, report)).To(Succeed())
+
+	return report
+}
+
+func (s *JUnitSuite) TestReport(t T) {
+	dir, err := ioutil.TempDir("", "sweet-junit")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "reports", "junit.xml")
+	s.runReporter(path, "example.com/pkg")
+
+	report := s.readReport(path)
+	Expect(report.Tests).To(Equal(5))
+	Expect(report.Failures).To(Equal(1))
+	Expect(report.Errors).To(Equal(1))
+	Expect(report.Skipped).To(Equal(1))
+	Expect(report.Suites).To(HaveLen(1))
+
+	suite := report.Suites[0]
+	Expect(suite.Name).To(Equal("example.com/pkg/MySuite"))
+	Expect(suite.Time).To(Equal("3.000"))
+	Expect(suite.Properties.Properties).To(Equal([]*junitProperty{
+		{Name: "tag", Value: "integration"},
+	}))
+	Expect(suite.Cases).To(HaveLen(5))
+
+	Expect(suite.Cases[0].Name).To(Equal("TestPass/Sub"))
+	Expect(suite.Cases[1].Name).To(Equal("TestPass"))
+	Expect(suite.Cases[1].ClassName).To(Equal("example.com/pkg.MySuite"))
+	Expect(suite.Cases[1].SystemOut.Body).To(Equal("logged"))
+
+	Expect(suite.Cases[2].Failure.Message).To(Equal("Expected"))
+	Expect(suite.Cases[2].Failure.Body).To(HavePrefix("/src/my_test.go:20\n\nExpected"))
+	Expect(suite.Cases[3].Error.Type).To(Equal("Panic"))
+	Expect(suite.Cases[4].Skipped.Message).To(Equal("not today"))
+	Expect(suite.Cases[1].Properties).To(BeNil())
+	Expect(suite.Cases[4].Properties.Properties).To(Equal([]*junitProperty{
+		{Name: "tag", Value: "slow"},
+		{Name: "tag", Value: "flaky"},
+	}))
+
+	_, err = os.Stat(path + ".lock")
+	Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func (s *JUnitSuite) TestMerge(t T) {
+	dir, err := ioutil.TempDir("", "sweet-junit")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "junit.xml")
+	s.runReporter(path, "example.com/first")
+	s.runReporter(path, "example.com/second")
+	// Running a package again replaces its previous results
+	s.runReporter(path, "example.com/first")
+
+	report := s.readReport(path)
+	Expect(report.Tests).To(Equal(10))
+	Expect(report.Suites).To(HaveLen(2))
+	Expect(report.Suites[0].Package).To(Equal("example.com/second"))
+	Expect(report.Suites[1].Package).To(Equal("example.com/first"))
+}
+
+func (s *JUnitSuite) TestQuarantined(t T) {
+	dir, err := ioutil.TempDir("", "sweet-junit")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "junit.xml")
+
+	p := newJUnitReporter()
+	p.pkg = "example.com/pkg"
+	p.SetOption("output", path)
+
+	testName := newTestName("MySuite", []string{"TestFlaky"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestQuarantined(testName, &TestFailedStats{
+		Name:        testName,
+		Message:     "boom",
+		Quarantined: true,
+	})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{})
+	p.Finished()
+
+	report := s.readReport(path)
+	Expect(report.Failures).To(BeZero())
+	Expect(report.Skipped).To(Equal(1))
+	Expect(report.Suites[0].Cases[0].Skipped.Message).To(Equal("Quarantined: boom"))
+	Expect(report.Suites[0].Cases[0].SystemOut.Body).To(Equal("boom"))
+}
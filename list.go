@@ -0,0 +1,116 @@
+package sweet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// The formats -sweet.list can print the tests in.
+const (
+	listText = "text"
+	listJSON = "json"
+)
+
+var listFormats = []string{listText, listJSON}
+
+// listedPackage is the test tree printed by -sweet.list.
+type listedPackage struct {
+	Package string
+	Suites  []*listedSuite
+}
+
+type listedSuite struct {
+	Name  string
+	Tests []*listedTest
+}
+
+type listedTest struct {
+	// Name is the name of the test method and Test is the full name of the
+	// test, such as "MySuite/TestThing".
+	Name string
+	Test string
+	Tags []string `json:",omitempty"`
+}
+
+func checkListFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, name := range listFormats {
+		if format == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown list format \"%s\", must be one of %s", format, strings.Join(listFormats, ", "))
+}
+
+// listTests finds the suites and tests that would be run, in the order
+// they'd run in, without calling any set ups, tear downs or tests. Subtests
+// are only known once their test runs so they aren't included.
+func (s *S) listTests() *listedPackage {
+	pkg := &listedPackage{
+		Package: packageImportPath(),
+		Suites:  make([]*listedSuite, 0),
+	}
+
+	for _, runner := range s.suiteRunners {
+		suiteName := runner.name()
+		if !runner.included(suiteName) {
+			continue
+		}
+
+		suite := &listedSuite{
+			Name:  suiteName,
+			Tests: make([]*listedTest, 0),
+		}
+
+		suiteType := reflect.TypeOf(runner.suite)
+		for _, idx := range runner.selectTests(suiteName, suiteType) {
+			testName := runner.taggedTestName(suiteName, suiteType.Method(idx).Name)
+			suite.Tests = append(suite.Tests, &listedTest{
+				Name: suiteType.Method(idx).Name,
+				Test: testName.String(),
+				Tags: testName.Tags,
+			})
+		}
+		if len(flagTags) > 0 && len(suite.Tests) == 0 {
+			continue
+		}
+
+		pkg.Suites = append(pkg.Suites, suite)
+	}
+
+	return pkg
+}
+
+// printTests writes the suites and tests that would be run in the format
+// given with -sweet.list.
+func (s *S) printTests(out io.Writer, format string) error {
+	pkg := s.listTests()
+
+	if format == listJSON {
+		data, err := json.MarshalIndent(pkg, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	for _, suite := range pkg.Suites {
+		fmt.Fprintf(out, "%s\n", suite.Name)
+		for _, test := range suite.Tests {
+			if len(test.Tags) > 0 {
+				fmt.Fprintf(out, "    %s [%s]\n", test.Name, strings.Join(test.Tags, ", "))
+			} else {
+				fmt.Fprintf(out, "    %s\n", test.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package sweet
+
+import (
+	"bytes"
+
+	. "github.com/onsi/gomega"
+)
+
+type ListSuite struct{}
+
+func (s *ListSuite) TestListTests(t T) {
+	oldInclude := flagInclude
+	defer func() { flagInclude = oldInclude }()
+
+	sweetS := &S{}
+	sweetS.AddSuite(&orderFirstSuite{})
+	sweetS.AddSuite(&orderSecondSuite{})
+
+	pkg := sweetS.listTests()
+	Expect(pkg.Package).To(Equal(packageImportPath()))
+	Expect(pkg.Suites).To(HaveLen(2))
+	Expect(pkg.Suites[0].Name).To(Equal("orderFirstSuite"))
+	Expect(pkg.Suites[1].Name).To(Equal("orderSecondSuite"))
+	Expect(pkg.Suites[1].Tests).To(Equal([]*listedTest{
+		{Name: "TestA", Test: "orderSecondSuite/TestA"},
+		{Name: "TestB", Test: "orderSecondSuite/TestB"},
+		{Name: "TestC", Test: "orderSecondSuite/TestC"},
+	}))
+
+	flagInclude = stringSliceFlags{"orderfirstsuite"}
+	pkg = sweetS.listTests()
+	Expect(pkg.Suites).To(HaveLen(1))
+	Expect(pkg.Suites[0].Name).To(Equal("orderFirstSuite"))
+}
+
+func (s *ListSuite) TestPrintTests(t T) {
+	sweetS := &S{}
+	sweetS.AddSuite(&orderFirstSuite{})
+
+	buf := &bytes.Buffer{}
+	Expect(sweetS.printTests(buf, listText)).To(BeNil())
+	Expect(buf.String()).To(Equal("orderFirstSuite\n    TestA\n"))
+
+	buf.Reset()
+	Expect(sweetS.printTests(buf, listJSON)).To(BeNil())
+	Expect(buf.String()).To(MatchJSON(`{
+		"Package": "` + packageImportPath() + `",
+		"Suites": [{
+			"Name": "orderFirstSuite",
+			"Tests": [{"Name": "TestA", "Test": "orderFirstSuite/TestA"}]
+		}]
+	}`))
+}
+
+func (s *ListSuite) TestPrintTags(t T) {
+	oldTags := flagTags
+	defer func() { flagTags = oldTags }()
+
+	sweetS := &S{}
+	sweetS.AddSuite(&orderFirstSuite{})
+	sweetS.AddSuite(&tagsTaggedSuite{}, WithTags("db"))
+
+	buf := &bytes.Buffer{}
+	Expect(sweetS.printTests(buf, listText)).To(BeNil())
+	Expect(buf.String()).To(Equal(
+		"orderFirstSuite\n" +
+			"    TestA\n" +
+			"tagsTaggedSuite\n" +
+			"    TestFast [db]\n" +
+			"    TestSlow [db, slow, integration]\n",
+	))
+
+	// Suites without any matching tests are left out
+	flagTags = stringSliceFlags{"!slow"}
+	buf.Reset()
+	Expect(sweetS.printTests(buf, listText)).To(BeNil())
+	Expect(buf.String()).To(Equal(
+		"orderFirstSuite\n" +
+			"    TestA\n" +
+			"tagsTaggedSuite\n" +
+			"    TestFast [db]\n",
+	))
+
+	flagTags = stringSliceFlags{"slow"}
+	buf.Reset()
+	Expect(sweetS.printTests(buf, listText)).To(BeNil())
+	Expect(buf.String()).To(Equal(
+		"tagsTaggedSuite\n" +
+			"    TestSlow [db, slow, integration]\n",
+	))
+}
+
+func (s *ListSuite) TestCheckListFormat(t T) {
+	Expect(checkListFormat("")).To(BeNil())
+	Expect(checkListFormat("text")).To(BeNil())
+	Expect(checkListFormat("json")).To(BeNil())
+	Expect(checkListFormat("xml")).ToNot(BeNil())
+}
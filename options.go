@@ -2,7 +2,11 @@ package sweet
 
 import (
 	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type stringSliceFlags []string
@@ -17,17 +21,169 @@ func (f *stringSliceFlags) Set(value string) error {
 }
 
 var (
-	flagSkipRuns       bool
-	flagOpts           stringSliceFlags
-	flagHelp           = flag.Bool("sweet.help", false, "Shows help information for sweet and registered plugins")
-	flagExtended       = flag.Bool("sweet.extended", false, "Shows extended error information for failed tests")
-	flagInclude        stringSliceFlags
-	flagExclude        stringSliceFlags
-	flagParallelSuites = flag.Bool("sweet.parallelsuites", false, "Suites will be run in parallel instead of synchronously.")
+	flagSkipRuns         bool
+	flagOpts             stringSliceFlags
+	flagConfig           = flag.String("sweet.config", "", "Path of the sweet configuration file to use instead of searching for one, or \"none\"")
+	flagReporter         = flag.String("sweet.reporter", "default", "Reporters to use for test results, separated by commas")
+	flagHelp             = flag.Bool("sweet.help", false, "Shows help information for sweet and registered plugins")
+	flagExtended         = flag.Bool("sweet.extended", false, "Shows extended error information for failed tests")
+	flagInclude          stringSliceFlags
+	flagExclude          stringSliceFlags
+	flagHide             stringSliceFlags
+	flagTags             stringSliceFlags
+	flagParallelSuites   = flag.Bool("sweet.parallelsuites", false, "Suites will be run in parallel instead of synchronously.")
+	flagSnippet          = flag.Int("sweet.snippet", 0, "Number of lines of source to show around each line of a failure")
+	flagFullPaths        = flag.Bool("sweet.fullpaths", false, "Show failure file paths relative to the package instead of only the file name")
+	flagSlowest          = flag.Int("sweet.slowest", 0, "Number of the slowest tests to list after the results")
+	flagBudget           = flag.Duration("sweet.budget", 0, "Fail any test that takes longer than this to run")
+	flagHistory          = flag.String("sweet.history", "", "Directory to save the results of each run to")
+	flagQuarantine       = flag.String("sweet.quarantine", "", "File listing flaky tests whose failures don't fail the run")
+	flagUpdateQuarantine = flag.Bool("sweet.updatequarantine", false, "Add flaky tests found in the history to the quarantine file")
+	flagResults          = flag.String("sweet.results", "", "Directory to save the failed tests of each package to, \"cache\" for the user cache directory or \"none\"")
+	flagRerunFailed      = flag.Bool("sweet.rerunfailed", false, "Only run the tests that failed the last time they ran")
+	flagOrder            = flag.String("sweet.order", "default", "Order to run suites and tests in")
+	flagFailEmpty        = flag.Bool("sweet.failempty", false, "Fail the run if no tests were run")
+	flagMaxSkips         = flag.Int("sweet.maxskips", -1, "Fail the run if more than this percentage of tests were skipped")
+	flagFailDeprecated   = flag.Bool("sweet.faildeprecated", false, "Fail the run if any test methods use a deprecated signature")
+	flagSuiteBudget      = flag.Duration("sweet.suitebudget", 0, "Fail the run if any suite takes longer than this to run")
+	flagList             = flag.String("sweet.list", "", "List the suites and tests that would be run as text or json instead of running them")
 )
 
 func init() {
 	flag.Var(&flagOpts, "sweet.opt", "Option to provide to a sweet plugin in the format \"plugin.setting=value\"")
 	flag.Var(&flagInclude, "sweet.include", "Only run tests that match the provided expression")
 	flag.Var(&flagExclude, "sweet.exclude", "Do not include tests that match the provided expression")
+	flag.Var(&flagHide, "sweet.hide", "Hide failure frames from the provided packages, separated by commas")
+	flag.Var(&flagTags, "sweet.tags", "Only run tests with tags matching the expression, such as \"integration,!slow\"")
+}
+
+// Validate checks that a value is valid for the option, returning the value
+// the plugin should be given.
+func (o *PluginOption) Validate(value string) (string, error) {
+	switch o.Type {
+	case OptionBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("\"%s\" is not a valid bool", value)
+		}
+		return strconv.FormatBool(b), nil
+	case OptionInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("\"%s\" is not a valid int", value)
+		}
+	case OptionDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return "", fmt.Errorf("\"%s\" is not a valid duration", value)
+		}
+	case OptionPath:
+		if value == "" {
+			return "", fmt.Errorf("a path is required")
+		}
+		return filepath.Clean(value), nil
+	case OptionEnum:
+		for _, allowed := range o.Values {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("\"%s\" must be one of %s", value, strings.Join(o.Values, ", "))
+	}
+
+	return value, nil
+}
+
+// parseFlagOpts splits the -sweet.opt values into their plugin prefix, option
+// name and value.
+func parseFlagOpts(opts []string) (map[string]map[string]string, error) {
+	res := make(map[string]map[string]string)
+	for _, opt := range opts {
+		valIdx := strings.Index(opt, "=")
+		if valIdx < 0 {
+			return nil, fmt.Errorf("option \"%s\" is not in the format \"plugin.setting=value\"", opt)
+		}
+		name := opt[:valIdx]
+		value := opt[valIdx+1:]
+
+		dotIdx := strings.Index(name, ".")
+		if dotIdx <= 0 || dotIdx == len(name)-1 {
+			return nil, fmt.Errorf("option \"%s\" is not in the format \"plugin.setting=value\"", opt)
+		}
+
+		prefix := name[:dotIdx]
+		if _, ok := res[prefix]; !ok {
+			res[prefix] = make(map[string]string)
+		}
+		res[prefix][name[dotIdx+1:]] = value
+	}
+
+	return res, nil
+}
+
+// optionEnvName is the environment variable that can be used to set a plugin
+// option, such as SWEET_JUNIT_OUTPUT for the junit.output option.
+func optionEnvName(prefix string, name string) string {
+	envName := strings.ToUpper("sweet_" + prefix + "_" + name)
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, envName)
+}
+
+// resolvePluginOptions works out the value of each of a plugin's options from
+// the values provided with -sweet.opt, then the environment, then the
+// configuration file and finally the option defaults. Options that don't have
+// a value from any of those aren't included. Path options are passed to
+// resolvePath along with whether they came from the configuration file.
+func resolvePluginOptions(
+	opts *PluginOptions,
+	flagValues map[string]string,
+	configValues map[string]string,
+	lookupEnv func(key string) (string, bool),
+	resolvePath func(path string, fromConfig bool) string,
+) (map[string]string, error) {
+	for name := range flagValues {
+		if _, ok := opts.Options[name]; !ok {
+			return nil, fmt.Errorf("unknown option \"%s.%s\"", opts.Prefix, name)
+		}
+	}
+	for name := range configValues {
+		if _, ok := opts.Options[name]; !ok {
+			return nil, fmt.Errorf("unknown option \"%s.%s\" in the configuration file", opts.Prefix, name)
+		}
+	}
+
+	res := make(map[string]string)
+	for name, opt := range opts.Options {
+		value, ok := flagValues[name]
+		source := "-sweet.opt"
+		if !ok {
+			value, ok = lookupEnv(optionEnvName(opts.Prefix, name))
+			source = optionEnvName(opts.Prefix, name)
+		}
+		if !ok {
+			value, ok = configValues[name]
+			source = "the configuration file"
+		}
+		if !ok {
+			if opt.Default == "" {
+				continue
+			}
+			value = opt.Default
+			source = "the default"
+		}
+
+		valid, err := opt.Validate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for option \"%s.%s\" from %s: %s",
+				opts.Prefix, name, source, err)
+		}
+		if opt.Type == OptionPath {
+			valid = resolvePath(valid, source == "the configuration file")
+		}
+		res[name] = valid
+	}
+
+	return res, nil
 }
@@ -0,0 +1,134 @@
+package sweet
+
+import (
+	. "github.com/onsi/gomega"
+)
+
+type OptionsSuite struct{}
+
+func (s *OptionsSuite) TestValidate(t T) {
+	boolOpt := &PluginOption{Type: OptionBool}
+	v, err := boolOpt.Validate("1")
+	Expect(err).To(BeNil())
+	Expect(v).To(Equal("true"))
+	_, err = boolOpt.Validate("maybe")
+	Expect(err).ToNot(BeNil())
+
+	intOpt := &PluginOption{Type: OptionInt}
+	v, err = intOpt.Validate("42")
+	Expect(err).To(BeNil())
+	Expect(v).To(Equal("42"))
+	_, err = intOpt.Validate("4.2")
+	Expect(err).ToNot(BeNil())
+
+	durOpt := &PluginOption{Type: OptionDuration}
+	_, err = durOpt.Validate("1m30s")
+	Expect(err).To(BeNil())
+	_, err = durOpt.Validate("90")
+	Expect(err).ToNot(BeNil())
+
+	pathOpt := &PluginOption{Type: OptionPath}
+	v, err = pathOpt.Validate("out//reports/")
+	Expect(err).To(BeNil())
+	Expect(v).To(Equal("out/reports"))
+	_, err = pathOpt.Validate("")
+	Expect(err).ToNot(BeNil())
+
+	enumOpt := &PluginOption{Type: OptionEnum, Values: []string{"a", "b"}}
+	v, err = enumOpt.Validate("b")
+	Expect(err).To(BeNil())
+	Expect(v).To(Equal("b"))
+	_, err = enumOpt.Validate("c")
+	Expect(err).ToNot(BeNil())
+
+	strOpt := &PluginOption{}
+	v, err = strOpt.Validate("")
+	Expect(err).To(BeNil())
+	Expect(v).To(Equal(""))
+}
+
+func (s *OptionsSuite) TestParseFlagOpts(t T) {
+	res, err := parseFlagOpts([]string{"junit.output=out.xml", "junit.merge=", "tap.version=14"})
+	Expect(err).To(BeNil())
+	Expect(res).To(Equal(map[string]map[string]string{
+		"junit": {"output": "out.xml", "merge": ""},
+		"tap":   {"version": "14"},
+	}))
+
+	_, err = parseFlagOpts([]string{"junit.output"})
+	Expect(err).ToNot(BeNil())
+	_, err = parseFlagOpts([]string{"output=out.xml"})
+	Expect(err).ToNot(BeNil())
+	_, err = parseFlagOpts([]string{"junit.=out.xml"})
+	Expect(err).ToNot(BeNil())
+}
+
+func (s *OptionsSuite) TestOptionEnvName(t T) {
+	Expect(optionEnvName("junit", "output")).To(Equal("SWEET_JUNIT_OUTPUT"))
+	Expect(optionEnvName("my-plugin", "some.opt")).To(Equal("SWEET_MY_PLUGIN_SOME_OPT"))
+}
+
+func (s *OptionsSuite) TestResolvePluginOptions(t T) {
+	opts := &PluginOptions{
+		Prefix: "plug",
+		Options: map[string]*PluginOption{
+			"fromflag":    {Type: OptionInt, Default: "1"},
+			"fromenv":     {Type: OptionInt, Default: "2"},
+			"fromdefault": {Type: OptionInt, Default: "3"},
+			"unset":       {Type: OptionInt},
+		},
+	}
+	env := map[string]string{
+		"SWEET_PLUG_FROMFLAG": "10",
+		"SWEET_PLUG_FROMENV":  "20",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	resolvePath := func(path string, fromConfig bool) string {
+		if fromConfig {
+			return "config/" + path
+		}
+		return "root/" + path
+	}
+
+	values, err := resolvePluginOptions(opts, map[string]string{"fromflag": "100"}, nil, lookupEnv, resolvePath)
+	Expect(err).To(BeNil())
+	Expect(values).To(Equal(map[string]string{
+		"fromflag":    "100",
+		"fromenv":     "20",
+		"fromdefault": "3",
+	}))
+
+	_, err = resolvePluginOptions(opts, map[string]string{"unknown": "1"}, nil, lookupEnv, resolvePath)
+	Expect(err).ToNot(BeNil())
+
+	// The environment takes priority over the configuration file, which
+	// takes priority over the default.
+	values, err = resolvePluginOptions(opts, nil, map[string]string{
+		"fromenv":     "200",
+		"fromdefault": "300",
+	}, lookupEnv, resolvePath)
+	Expect(err).To(BeNil())
+	Expect(values["fromenv"]).To(Equal("20"))
+	Expect(values["fromdefault"]).To(Equal("300"))
+
+	_, err = resolvePluginOptions(opts, nil, map[string]string{"unknown": "1"}, lookupEnv, resolvePath)
+	Expect(err).ToNot(BeNil())
+
+	// Paths are resolved depending on where they came from
+	opts.Options["report"] = &PluginOption{Type: OptionPath, Default: "report.xml"}
+	values, err = resolvePluginOptions(opts, nil, nil, lookupEnv, resolvePath)
+	Expect(err).To(BeNil())
+	Expect(values["report"]).To(Equal("root/report.xml"))
+	values, err = resolvePluginOptions(opts, nil, map[string]string{"report": "out/./report.xml"}, lookupEnv, resolvePath)
+	Expect(err).To(BeNil())
+	Expect(values["report"]).To(Equal("config/out/report.xml"))
+	delete(opts.Options, "report")
+
+	env["SWEET_PLUG_UNSET"] = "nope"
+	_, err = resolvePluginOptions(opts, nil, nil, lookupEnv, resolvePath)
+	Expect(err).ToNot(BeNil())
+	Expect(err.Error()).To(ContainSubstring("SWEET_PLUG_UNSET"))
+}
@@ -0,0 +1,269 @@
+package sweet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The orders suites and tests can be run in with -sweet.order.
+const (
+	orderDefault = "default"
+	orderFailed  = "failed"
+	orderSource  = "source"
+)
+
+var orderNames = []string{orderDefault, orderFailed, orderSource}
+
+// OrderedSuite is implemented by suites listing the order their tests should
+// run in, such as a suite where each test is a step in a scenario. Tests that
+// aren't listed run after the ones that are.
+type OrderedSuite interface {
+	OrderTests() []string
+}
+
+// InSourceOrder is an AddSuite option running the suite's tests in the order
+// they're declared in the source instead of alphabetically. The source needs
+// to be available when the tests run, which it is with "go test".
+func InSourceOrder() SuiteOption {
+	return func(runner *suiteRunner) {
+		runner.sourceOrder = true
+	}
+}
+
+func checkOrder(order string) error {
+	for _, name := range orderNames {
+		if order == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown order \"%s\", must be one of %s", order, strings.Join(orderNames, ", "))
+}
+
+// orderFailedFirst moves the suites that failed last time to the front,
+// followed by the suites whose source has changed since then. Otherwise the
+// suites keep the order they were added in.
+func orderFailedFirst(runners []*suiteRunner, last *failedTests) []*suiteRunner {
+	if last == nil {
+		return runners
+	}
+
+	rank := func(runner *suiteRunner) int {
+		name := runner.name()
+		for _, failed := range last.Failed {
+			if strings.HasPrefix(failed, name+"/") {
+				return 0
+			}
+		}
+		if runner.changedSince(last.Time) {
+			return 1
+		}
+		return 2
+	}
+
+	ranks := make(map[*suiteRunner]int, len(runners))
+	for _, runner := range runners {
+		ranks[runner] = rank(runner)
+	}
+
+	ordered := make([]*suiteRunner, len(runners))
+	copy(ordered, runners)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ranks[ordered[i]] < ranks[ordered[j]]
+	})
+
+	return ordered
+}
+
+// orderTests returns the indexes of the suite's test methods in the order
+// they should be run.
+func (s *suiteRunner) orderTests(suiteName string, suiteType reflect.Type) []int {
+	tests := make([]int, 0, suiteType.NumMethod())
+	for idx := 0; idx < suiteType.NumMethod(); idx++ {
+		if strings.HasPrefix(suiteType.Method(idx).Name, "Test") {
+			tests = append(tests, idx)
+		}
+	}
+
+	if s.sourceOrder || *flagOrder == orderSource {
+		sortByPosition(tests, suiteType, sourcePositions(s.suite))
+	}
+
+	// An explicit order takes priority over the source order
+	if ordered, ok := s.suite.(OrderedSuite); ok {
+		positions := make(map[string]int)
+		for idx, name := range ordered.OrderTests() {
+			if _, ok := positions[name]; !ok {
+				positions[name] = idx
+			}
+		}
+		sortByPosition(tests, suiteType, positions)
+	}
+
+	if *flagOrder == orderFailed && s.s.lastResults != nil {
+		failed := func(idx int) bool {
+			name := newTestName(suiteName, []string{suiteType.Method(idx).Name}).String()
+			for _, test := range s.s.lastResults.Failed {
+				if test == name || strings.HasPrefix(test, name+"/") {
+					return true
+				}
+			}
+			return false
+		}
+		sort.SliceStable(tests, func(i, j int) bool {
+			return failed(tests[i]) && !failed(tests[j])
+		})
+	}
+
+	return orderDependencies(tests, suiteType, suiteDependencies(s.suite))
+}
+
+// sortByPosition sorts the indexes of test methods by the position of each
+// method's name. Methods without a position are moved to the end.
+func sortByPosition(tests []int, suiteType reflect.Type, positions map[string]int) {
+	position := func(idx int) int {
+		if pos, ok := positions[suiteType.Method(idx).Name]; ok {
+			return pos
+		}
+		return len(positions)
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		return position(tests[i]) < position(tests[j])
+	})
+}
+
+// changedSince returns true if any of the files the suite's methods are in
+// have been modified after t.
+func (s *suiteRunner) changedSince(t time.Time) bool {
+	suiteType := reflect.TypeOf(s.suite)
+	for idx := 0; idx < suiteType.NumMethod(); idx++ {
+		fn := runtime.FuncForPC(suiteType.Method(idx).Func.Pointer())
+		if fn == nil {
+			continue
+		}
+		file, _ := fn.FileLine(fn.Entry())
+
+		fi, err := os.Stat(file)
+		if err == nil && fi.ModTime().After(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	parsedDirsLock sync.Mutex
+	parsedDirs     = make(map[string]map[string][]string)
+)
+
+// sourcePositions returns the position each of the suite's methods is
+// declared at in its source, starting from 0. The methods are found by
+// parsing the Go files in the directories the suite's methods were compiled
+// from, as well as the current directory in case all of them are value
+// receiver methods without their own source. Across files the methods are in
+// the order of the file names.
+func sourcePositions(suite interface{}) map[string]int {
+	suiteType := reflect.TypeOf(suite)
+	typeName := reflect.Indirect(reflect.ValueOf(suite)).Type().Name()
+
+	dirs := make([]string, 0)
+	seenDirs := make(map[string]bool)
+	addDir := func(dir string) {
+		if dir != "" && !seenDirs[dir] {
+			seenDirs[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for idx := 0; idx < suiteType.NumMethod(); idx++ {
+		fn := runtime.FuncForPC(suiteType.Method(idx).Func.Pointer())
+		if fn == nil {
+			continue
+		}
+		file, _ := fn.FileLine(fn.Entry())
+		if filepath.IsAbs(file) {
+			addDir(filepath.Dir(file))
+		}
+	}
+	if wd, err := os.Getwd(); err == nil {
+		addDir(wd)
+	}
+
+	positions := make(map[string]int)
+	for _, dir := range dirs {
+		methods := parseMethods(dir)[typeName]
+		if len(methods) == 0 {
+			continue
+		}
+		for idx, name := range methods {
+			positions[name] = idx
+		}
+		break
+	}
+
+	return positions
+}
+
+// parseMethods parses the Go files in dir and returns the methods declared
+// for each type in the order they're declared. Files that can't be parsed
+// are left out.
+func parseMethods(dir string) map[string][]string {
+	parsedDirsLock.Lock()
+	defer parsedDirsLock.Unlock()
+
+	if methods, ok := parsedDirs[dir]; ok {
+		return methods
+	}
+
+	methods := make(map[string][]string)
+	parsedDirs[dir] = methods
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return methods
+	}
+
+	// ReadDir sorts the files by name
+	fset := token.NewFileSet()
+	for _, fi := range files {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, fi.Name()), nil, 0)
+		if err != nil {
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+				continue
+			}
+
+			recvType := funcDecl.Recv.List[0].Type
+			if star, ok := recvType.(*ast.StarExpr); ok {
+				recvType = star.X
+			}
+			ident, ok := recvType.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			methods[ident.Name] = append(methods[ident.Name], funcDecl.Name.Name)
+		}
+	}
+
+	return methods
+}
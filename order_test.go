@@ -0,0 +1,136 @@
+package sweet
+
+import (
+	"reflect"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type OrderSuite struct{}
+
+type orderFirstSuite struct{}
+
+func (s *orderFirstSuite) TestA(t T) {}
+
+type orderSecondSuite struct{}
+
+func (s *orderSecondSuite) TestA(t T) {}
+func (s *orderSecondSuite) TestB(t T) {}
+func (s *orderSecondSuite) TestC(t T) {}
+func (s *orderSecondSuite) Helper()   {}
+
+type orderThirdSuite struct{}
+
+func (s *orderThirdSuite) TestA(t T) {}
+
+type orderScenarioSuite struct{}
+
+func (s *orderScenarioSuite) TestCreate(t T) {}
+func (s *orderScenarioSuite) TestRead(t T)   {}
+func (s *orderScenarioSuite) TestDelete(t T) {}
+
+type orderListedSuite struct{}
+
+func (s *orderListedSuite) OrderTests() []string {
+	return []string{"TestSecond", "TestFirst"}
+}
+func (s *orderListedSuite) TestFirst(t T)    {}
+func (s *orderListedSuite) TestSecond(t T)   {}
+func (s *orderListedSuite) TestUnlisted(t T) {}
+
+func (s *OrderSuite) TestCheckOrder(t T) {
+	Expect(checkOrder("default")).To(BeNil())
+	Expect(checkOrder("failed")).To(BeNil())
+	Expect(checkOrder("source")).To(BeNil())
+	Expect(checkOrder("random")).ToNot(BeNil())
+}
+
+func (s *OrderSuite) TestOrderFailedFirst(t T) {
+	sweetS := &S{}
+	first := newSuiteRunner(sweetS, &orderFirstSuite{})
+	second := newSuiteRunner(sweetS, &orderSecondSuite{})
+	third := newSuiteRunner(sweetS, &orderThirdSuite{})
+	runners := []*suiteRunner{first, second, third}
+
+	Expect(orderFailedFirst(runners, nil)).To(Equal(runners))
+
+	// Nothing has changed since a run in the future
+	ordered := orderFailedFirst(runners, &failedTests{
+		Time:   time.Now().Add(time.Hour),
+		Failed: []string{"orderThirdSuite/TestA"},
+	})
+	Expect(ordered).To(Equal([]*suiteRunner{third, first, second}))
+
+	// Every suite has changed since a run at the start of time, so only the
+	// failed suite is moved
+	ordered = orderFailedFirst(runners, &failedTests{
+		Failed: []string{"orderSecondSuite/TestB/Sub"},
+	})
+	Expect(ordered).To(Equal([]*suiteRunner{second, first, third}))
+}
+
+func (s *OrderSuite) TestOrderTests(t T) {
+	oldOrder := *flagOrder
+	defer func() { *flagOrder = oldOrder }()
+
+	sweetS := &S{
+		lastResults: &failedTests{
+			Failed: []string{"orderSecondSuite/TestC"},
+		},
+	}
+	runner := newSuiteRunner(sweetS, &orderSecondSuite{})
+
+	*flagOrder = orderDefault
+	Expect(testOrderNames(runner, "orderSecondSuite")).To(Equal([]string{"TestA", "TestB", "TestC"}))
+
+	*flagOrder = orderFailed
+	Expect(testOrderNames(runner, "orderSecondSuite")).To(Equal([]string{"TestC", "TestA", "TestB"}))
+}
+
+func testOrderNames(runner *suiteRunner, suiteName string) []string {
+	suiteType := reflect.TypeOf(runner.suite)
+
+	res := make([]string, 0)
+	for _, idx := range runner.orderTests(suiteName, suiteType) {
+		res = append(res, suiteType.Method(idx).Name)
+	}
+	return res
+}
+
+func (s *OrderSuite) TestSourceOrder(t T) {
+	oldOrder := *flagOrder
+	defer func() { *flagOrder = oldOrder }()
+	*flagOrder = orderDefault
+
+	positions := sourcePositions(&orderScenarioSuite{})
+	Expect(positions).To(Equal(map[string]int{
+		"TestCreate": 0,
+		"TestRead":   1,
+		"TestDelete": 2,
+	}))
+
+	sweetS := &S{}
+	runner := newSuiteRunner(sweetS, &orderScenarioSuite{})
+	Expect(testOrderNames(runner, "orderScenarioSuite")).To(Equal(
+		[]string{"TestCreate", "TestDelete", "TestRead"},
+	))
+
+	InSourceOrder()(runner)
+	Expect(testOrderNames(runner, "orderScenarioSuite")).To(Equal(
+		[]string{"TestCreate", "TestRead", "TestDelete"},
+	))
+
+	*flagOrder = orderSource
+	runner = newSuiteRunner(sweetS, &orderScenarioSuite{})
+	Expect(testOrderNames(runner, "orderScenarioSuite")).To(Equal(
+		[]string{"TestCreate", "TestRead", "TestDelete"},
+	))
+}
+
+func (s *OrderSuite) TestOrderedSuite(t T) {
+	runner := newSuiteRunner(&S{}, &orderListedSuite{})
+	Expect(testOrderNames(runner, "orderListedSuite")).To(Equal(
+		[]string{"TestSecond", "TestFirst", "TestUnlisted"},
+	))
+}
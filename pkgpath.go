@@ -0,0 +1,134 @@
+package sweet
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	pkgPathOnce sync.Once
+	pkgPath     string
+)
+
+// packageImportPath returns the import path of the package being tested.
+// "go test" runs test binaries in the package's directory, so it's worked out
+// from the working directory and the go.mod file above it, falling back to
+// GOPATH and then just the directory name.
+func packageImportPath() string {
+	pkgPathOnce.Do(func() {
+		wd, err := os.Getwd()
+		if err != nil {
+			pkgPath = "unknown"
+			return
+		}
+		pkgPath = importPathForDir(wd)
+	})
+
+	return pkgPath
+}
+
+func importPathForDir(dir string) string {
+	for modDir := dir; ; {
+		data, err := ioutil.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			if modPath := modulePath(data); modPath != "" {
+				rel, err := filepath.Rel(modDir, dir)
+				if err != nil || rel == "." {
+					return modPath
+				}
+				return path.Join(modPath, filepath.ToSlash(rel))
+			}
+		}
+
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			break
+		}
+		modDir = parent
+	}
+
+	slashDir := filepath.ToSlash(dir)
+	if idx := strings.LastIndex(slashDir, "/src/"); idx >= 0 {
+		return slashDir[idx+len("/src/"):]
+	}
+
+	return filepath.Base(dir)
+}
+
+// modulePath returns the module path from the contents of a go.mod file.
+func modulePath(modFile []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(modFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+
+		modPath := strings.TrimSpace(line[len("module"):])
+		if idx := strings.Index(modPath, "//"); idx >= 0 {
+			modPath = strings.TrimSpace(modPath[:idx])
+		}
+		return strings.Trim(modPath, "\"`")
+	}
+
+	return ""
+}
+
+// findModuleRoot returns the closest directory at or above dir containing a
+// go.mod file, or an empty string if dir isn't in a module.
+func findModuleRoot(dir string) string {
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// projectPath resolves a relative path given to sweet for a test running in
+// dir. A path set in the configuration file is relative to the file,
+// otherwise it's relative to the root of the module so every package in the
+// module uses the same path.
+func projectPath(path string, cfg *config, fromConfig bool, dir string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	if fromConfig && cfg != nil {
+		return filepath.Join(filepath.Dir(cfg.Path), path)
+	}
+
+	root := findModuleRoot(dir)
+	if root == "" {
+		root = dir
+	}
+
+	return filepath.Join(root, path)
+}
+
+// findRepoRoot returns the closest directory at or above dir containing a
+// .git directory or file, or an empty string if dir isn't in a repository.
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
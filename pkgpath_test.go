@@ -0,0 +1,40 @@
+package sweet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/gomega"
+)
+
+type PkgPathSuite struct{}
+
+func (s *PkgPathSuite) TestModulePath(t T) {
+	Expect(modulePath([]byte("module github.com/aphistic/sweet\n\ngo 1.12\n"))).
+		To(Equal("github.com/aphistic/sweet"))
+	Expect(modulePath([]byte("// comment\nmodule \"example.com/quoted\" // trailing\n"))).
+		To(Equal("example.com/quoted"))
+	Expect(modulePath([]byte("go 1.12\n"))).To(Equal(""))
+}
+
+func (s *PkgPathSuite) TestImportPathForDir(t T) {
+	root, err := ioutil.TempDir("", "sweet-pkgpath")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(root)
+
+	pkgDir := filepath.Join(root, "sub", "pkg")
+	Expect(os.MkdirAll(pkgDir, 0755)).To(Succeed())
+	Expect(ioutil.WriteFile(
+		filepath.Join(root, "go.mod"),
+		[]byte("module example.com/mod\n"),
+		0644,
+	)).To(Succeed())
+
+	Expect(importPathForDir(root)).To(Equal("example.com/mod"))
+	Expect(importPathForDir(pkgDir)).To(Equal("example.com/mod/sub/pkg"))
+}
+
+func (s *PkgPathSuite) TestPackageImportPath(t T) {
+	Expect(packageImportPath()).To(Equal(packageName))
+}
@@ -4,28 +4,163 @@ import (
 	"time"
 )
 
+// PluginAPIVersion is the version of the plugin interfaces provided by this
+// version of sweet. It's increased when new optional interfaces are added so
+// plugins depending on them can make sure they're supported.
+const PluginAPIVersion = 4
+
+// Plugin is the only interface a plugin is required to implement. Everything
+// else a plugin is interested in is opted into by also implementing one or
+// more of the listener interfaces below, which are detected when the plugin
+// is registered. Embedding BasePlugin is an easy way to get started.
 type Plugin interface {
 	Name() string
+}
+
+// VersionedPlugin is implemented by plugins requiring a minimum version of the
+// plugin API. Registering a plugin requiring a newer version than the one
+// provided by sweet is an error.
+type VersionedPlugin interface {
+	PluginAPIVersion() int
+}
+
+// OptionsPlugin is implemented by plugins that accept options provided using
+// -sweet.opt.
+type OptionsPlugin interface {
 	Options() *PluginOptions
 	SetOption(name, value string)
+}
 
+// RunStartingListener is notified before any suites are run.
+type RunStartingListener interface {
 	Starting()
+}
+
+// RunFinishedListener is notified after every suite has finished.
+type RunFinishedListener interface {
+	Finished()
+}
+
+// SuiteListener is notified when each suite starts and finishes.
+type SuiteListener interface {
 	SuiteStarting(suite string)
+	SuiteFinished(suite string, stats *SuiteFinishedStats)
+}
+
+// TestListener is notified about tests starting and their results. Subtests
+// started with T.Run are also sent here unless the plugin implements
+// SubtestListener.
+type TestListener interface {
 	TestStarting(testName *TestName)
 	TestPassed(testName *TestName, stats *TestPassedStats)
 	TestFailed(testName *TestName, stats *TestFailedStats)
 	TestSkipped(testName *TestName, stats *TestSkippedStats)
-	SuiteFinished(suite string, stats *SuiteFinishedStats)
-	Finished()
 }
 
+// SubtestListener is notified about subtests started with T.Run instead of
+// them being sent to TestListener.
+type SubtestListener interface {
+	SubtestStarting(testName *TestName)
+	SubtestPassed(testName *TestName, stats *TestPassedStats)
+	SubtestFailed(testName *TestName, stats *TestFailedStats)
+	SubtestSkipped(testName *TestName, stats *TestSkippedStats)
+}
+
+// OutputListener is notified of each message a test logs using T.Log and
+// friends as it's logged.
+type OutputListener interface {
+	TestOutput(testName *TestName, output string)
+}
+
+// QuarantineListener is notified when a test in the quarantine file fails.
+// These failures don't fail the run. Plugins that don't implement it receive
+// them as TestFailed or SubtestFailed with Quarantined set in the stats. It
+// was added in version 3 of the plugin API.
+type QuarantineListener interface {
+	TestQuarantined(testName *TestName, stats *TestFailedStats)
+}
+
+// SuiteStartingStatsListener is notified when each suite starts along with
+// stats about the suite, such as its tags. Plugins implementing it receive
+// this instead of SuiteListener's SuiteStarting. It was added in version 4 of
+// the plugin API.
+type SuiteStartingStatsListener interface {
+	SuiteStartingWithStats(suite string, stats *SuiteStartingStats)
+}
+
+// BasePlugin implements the original set of plugin methods as no-ops so a
+// plugin can embed it and only implement the events it cares about. It
+// intentionally doesn't implement SubtestListener, OutputListener,
+// QuarantineListener or SuiteStartingStatsListener because implementing those
+// changes which events a plugin receives.
+type BasePlugin struct{}
+
+func (BasePlugin) Options() *PluginOptions      { return nil }
+func (BasePlugin) SetOption(name, value string) {}
+
+func (BasePlugin) Starting()                                               {}
+func (BasePlugin) SuiteStarting(suite string)                              {}
+func (BasePlugin) TestStarting(testName *TestName)                         {}
+func (BasePlugin) TestPassed(testName *TestName, stats *TestPassedStats)   {}
+func (BasePlugin) TestFailed(testName *TestName, stats *TestFailedStats)   {}
+func (BasePlugin) TestSkipped(testName *TestName, stats *TestSkippedStats) {}
+func (BasePlugin) SuiteFinished(suite string, stats *SuiteFinishedStats)   {}
+func (BasePlugin) Finished()                                               {}
+
 type PluginOptions struct {
 	Prefix  string
 	Options map[string]*PluginOption
 }
 type PluginOption struct {
-	Help    string
+	Help string
+	// Default is passed to SetOption when the option isn't provided. Leave
+	// it empty to only call SetOption for options that were provided.
 	Default string
+	Type    OptionType
+	// Values are the allowed values when Type is OptionEnum.
+	Values []string
+}
+
+// OptionType is the kind of value a plugin option accepts. Values are checked
+// before they're passed to SetOption so plugins only see valid values.
+type OptionType int
+
+const (
+	// OptionString accepts any value.
+	OptionString OptionType = iota
+	// OptionBool accepts the values strconv.ParseBool does, which are passed
+	// to the plugin as "true" or "false".
+	OptionBool
+	// OptionInt accepts a base 10 integer.
+	OptionInt
+	// OptionDuration accepts a value time.ParseDuration does.
+	OptionDuration
+	// OptionPath accepts a non-empty file path, which is cleaned before it's
+	// passed to the plugin. Relative paths are made relative to the
+	// configuration file when they're set there, otherwise to the root of the
+	// module, so each package doesn't write its own file.
+	OptionPath
+	// OptionEnum accepts one of the option's Values.
+	OptionEnum
+)
+
+func (ot OptionType) String() string {
+	switch ot {
+	case OptionString:
+		return "string"
+	case OptionBool:
+		return "bool"
+	case OptionInt:
+		return "int"
+	case OptionDuration:
+		return "duration"
+	case OptionPath:
+		return "path"
+	case OptionEnum:
+		return "enum"
+	default:
+		return "unknown"
+	}
 }
 
 type TestPassedStats struct {
@@ -37,17 +172,49 @@ type TestFailedStats struct {
 	Time    time.Duration
 	Message string
 	Frames  []*TestFailedFrame
+	// Output is everything the test logged using T.Log and friends.
+	Output []string
+	// FailedSubtests are the subtests started with T.Run that failed. If
+	// the test only failed because of these there won't be a message or
+	// frames since the subtests are reported on their own.
+	FailedSubtests []*TestName
+
+	// Panicked is true when the test failed because of a panic sweet
+	// recovered from, such as a nil dereference or an index out of range.
+	// PanicValue is the value passed to panic and Stack is the unfiltered
+	// stack trace of the goroutine that panicked.
+	Panicked   bool
+	PanicValue interface{}
+	Stack      string
+
+	// Quarantined is true when the test is listed in the quarantine file so
+	// its failure doesn't fail the run.
+	Quarantined bool
 }
 type TestFailedFrame struct {
-	File   string
-	Line   int
-	Hidden bool
+	File     string
+	Line     int
+	Function string
+	Hidden   bool
+
+	// Source is the code surrounding Line when -sweet.snippet is used, it's
+	// nil for hidden frames or if the file couldn't be read.
+	Source *SourceSnippet
 }
 
 type TestSkippedStats struct {
 	Time time.Duration
+	// Message is the reason given when the test was skipped.
+	Message string
+}
+
+type SuiteStartingStats struct {
+	// Tags are the tags the suite was added with.
+	Tags []string
 }
 
 type SuiteFinishedStats struct {
 	Time time.Duration
+	// Tags are the tags the suite was added with.
+	Tags []string
 }
@@ -0,0 +1,125 @@
+package sweet
+
+import (
+	"fmt"
+
+	. "github.com/onsi/gomega"
+)
+
+type PluginSuite struct{}
+
+type recordingPlugin struct {
+	BasePlugin
+	events []string
+}
+
+func (p *recordingPlugin) Name() string { return "Recording" }
+func (p *recordingPlugin) TestStarting(testName *TestName) {
+	p.events = append(p.events, "TestStarting:"+testName.String())
+}
+func (p *recordingPlugin) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.events = append(p.events, "TestPassed:"+testName.String())
+}
+
+type subtestPlugin struct {
+	recordingPlugin
+}
+
+func (p *subtestPlugin) SubtestStarting(testName *TestName) {
+	p.events = append(p.events, "SubtestStarting:"+testName.String())
+}
+func (p *subtestPlugin) SubtestPassed(testName *TestName, stats *TestPassedStats) {
+	p.events = append(p.events, "SubtestPassed:"+testName.String())
+}
+func (p *subtestPlugin) SubtestFailed(testName *TestName, stats *TestFailedStats)   {}
+func (p *subtestPlugin) SubtestSkipped(testName *TestName, stats *TestSkippedStats) {}
+func (p *subtestPlugin) TestOutput(testName *TestName, output string) {
+	p.events = append(p.events, "TestOutput:"+output)
+}
+
+type namePlugin struct{}
+
+func (p *namePlugin) Name() string { return "Name Only" }
+
+func (s *PluginSuite) TestBasePlugin(t T) {
+	var plugin interface{} = &recordingPlugin{}
+
+	_, ok := plugin.(OptionsPlugin)
+	Expect(ok).To(BeTrue())
+	_, ok = plugin.(TestListener)
+	Expect(ok).To(BeTrue())
+	_, ok = plugin.(SuiteListener)
+	Expect(ok).To(BeTrue())
+
+	_, ok = plugin.(SubtestListener)
+	Expect(ok).To(BeFalse())
+	_, ok = plugin.(OutputListener)
+	Expect(ok).To(BeFalse())
+}
+
+func (s *PluginSuite) TestSubtestRouting(t T) {
+	testPlugin := &recordingPlugin{}
+	subPlugin := &subtestPlugin{}
+	sw := &S{
+		plugins: []Plugin{testPlugin, subPlugin, &namePlugin{}},
+	}
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	subName := newSubtestName(testName, "Sub")
+
+	sw.emitTestStarting(testName)
+	sw.emitTestStarting(subName)
+	sw.emitTestPassed(subName, &TestPassedStats{})
+	sw.emitTestPassed(testName, &TestPassedStats{})
+	sw.emitTestOutput(testName, "logged")
+
+	Expect(testPlugin.events).To(Equal([]string{
+		"TestStarting:MySuite/TestThing",
+		"TestStarting:MySuite/TestThing/Sub",
+		"TestPassed:MySuite/TestThing/Sub",
+		"TestPassed:MySuite/TestThing",
+	}))
+	Expect(subPlugin.events).To(Equal([]string{
+		"TestStarting:MySuite/TestThing",
+		"SubtestStarting:MySuite/TestThing/Sub",
+		"SubtestPassed:MySuite/TestThing/Sub",
+		"TestPassed:MySuite/TestThing",
+		"TestOutput:logged",
+	}))
+}
+
+type quarantinePlugin struct {
+	recordingPlugin
+}
+
+func (p *quarantinePlugin) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	p.events = append(p.events, "TestQuarantined:"+testName.String())
+}
+
+type failurePlugin struct {
+	recordingPlugin
+}
+
+func (p *failurePlugin) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.events = append(p.events, fmt.Sprintf("TestFailed:%s:%t", testName, stats.Quarantined))
+}
+
+func (s *PluginSuite) TestQuarantineRouting(t T) {
+	quarantine := &quarantinePlugin{}
+	failure := &failurePlugin{}
+	sw := &S{
+		plugins: []Plugin{quarantine, failure},
+	}
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	sw.emitTestFailed(testName, &TestFailedStats{Quarantined: true})
+	sw.emitTestFailed(testName, &TestFailedStats{})
+
+	Expect(quarantine.events).To(Equal([]string{
+		"TestQuarantined:MySuite/TestThing",
+	}))
+	Expect(failure.events).To(Equal([]string{
+		"TestFailed:MySuite/TestThing:true",
+		"TestFailed:MySuite/TestThing:false",
+	}))
+}
@@ -0,0 +1,90 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// The exit codes used when a run fails because of a policy rather than a
+// failed test, which exits with 1 as usual. When more than one policy fails
+// the code of the first one is used.
+const (
+	exitEmptyRun       = 3
+	exitTooManySkips   = 4
+	exitDeprecated     = 5
+	exitSuiteOverLimit = 6
+)
+
+// policyFailure is a policy the run didn't meet.
+type policyFailure struct {
+	Code    int
+	Flag    string
+	Message string
+}
+
+// checkPolicies returns the policies the run didn't meet, given the stats of
+// each suite and the deprecated method signatures that were used.
+func checkPolicies(suites []*suiteStats, deprecatedUsages []string) []*policyFailure {
+	failures := make([]*policyFailure, 0)
+
+	var total, skipped int64
+	for _, suite := range suites {
+		total += suite.Passed + suite.Failed + suite.Skipped + suite.Quarantined
+		skipped += suite.Skipped
+	}
+
+	if *flagFailEmpty && total == 0 {
+		failures = append(failures, &policyFailure{
+			Code:    exitEmptyRun,
+			Flag:    "-sweet.failempty",
+			Message: "No tests were run, check the include and exclude filters",
+		})
+	}
+
+	if *flagMaxSkips >= 0 && total > 0 {
+		percent := float64(skipped) / float64(total) * 100
+		if percent > float64(*flagMaxSkips) {
+			failures = append(failures, &policyFailure{
+				Code: exitTooManySkips,
+				Flag: "-sweet.maxskips",
+				Message: fmt.Sprintf("%d of %d tests were skipped (%.0f%%), more than the limit of %d%%",
+					skipped, total, percent, *flagMaxSkips),
+			})
+		}
+	}
+
+	if *flagFailDeprecated && len(deprecatedUsages) > 0 {
+		failures = append(failures, &policyFailure{
+			Code: exitDeprecated,
+			Flag: "-sweet.faildeprecated",
+			Message: fmt.Sprintf("%d test methods use a deprecated method signature",
+				len(deprecatedUsages)),
+		})
+	}
+
+	if *flagSuiteBudget > 0 {
+		for _, suite := range suites {
+			if suite.Time <= *flagSuiteBudget {
+				continue
+			}
+			failures = append(failures, &policyFailure{
+				Code: exitSuiteOverLimit,
+				Flag: "-sweet.suitebudget",
+				Message: fmt.Sprintf("%s took %s, which is longer than the budget of %s",
+					suite.Name, suite.Time.Round(time.Millisecond), *flagSuiteBudget),
+			})
+		}
+	}
+
+	return failures
+}
+
+// printPolicyFailures writes a summary of the policies the run didn't meet.
+func printPolicyFailures(out io.Writer, failures []*policyFailure) {
+	fmt.Fprintf(out, "The run failed the following Sweet policies:\n")
+	for _, failure := range failures {
+		fmt.Fprintf(out, "  %s (%s, exit code %d)\n", failure.Message, failure.Flag, failure.Code)
+	}
+	fmt.Fprintf(out, "\n")
+}
@@ -0,0 +1,57 @@
+package sweet
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type PolicySuite struct{}
+
+func (s *PolicySuite) TestNoPolicies(t T) {
+	failures := checkPolicies([]*suiteStats{}, []string{"MySuite/TestOld"})
+	Expect(failures).To(BeEmpty())
+}
+
+func (s *PolicySuite) TestPolicies(t T) {
+	oldFailEmpty, oldMaxSkips := *flagFailEmpty, *flagMaxSkips
+	oldFailDeprecated, oldSuiteBudget := *flagFailDeprecated, *flagSuiteBudget
+	defer func() {
+		*flagFailEmpty, *flagMaxSkips = oldFailEmpty, oldMaxSkips
+		*flagFailDeprecated, *flagSuiteBudget = oldFailDeprecated, oldSuiteBudget
+	}()
+
+	*flagFailEmpty = true
+	*flagMaxSkips = 25
+	*flagFailDeprecated = true
+	*flagSuiteBudget = time.Second
+
+	failures := checkPolicies([]*suiteStats{}, []string{})
+	Expect(failures).To(HaveLen(1))
+	Expect(failures[0].Code).To(Equal(exitEmptyRun))
+
+	failures = checkPolicies([]*suiteStats{
+		{Name: "FastSuite", Passed: 3, Skipped: 1, Time: 10 * time.Millisecond},
+	}, []string{})
+	Expect(failures).To(BeEmpty())
+
+	failures = checkPolicies([]*suiteStats{
+		{Name: "FastSuite", Passed: 2, Skipped: 2, Time: 10 * time.Millisecond},
+		{Name: "SlowSuite", Passed: 1, Time: 1500 * time.Millisecond},
+	}, []string{"MySuite/TestOld"})
+	Expect(failures).To(HaveLen(3))
+	Expect(failures[0].Code).To(Equal(exitTooManySkips))
+	Expect(failures[0].Message).To(Equal("2 of 5 tests were skipped (40%), more than the limit of 25%"))
+	Expect(failures[1].Code).To(Equal(exitDeprecated))
+	Expect(failures[2].Code).To(Equal(exitSuiteOverLimit))
+	Expect(failures[2].Message).To(Equal("SlowSuite took 1.5s, which is longer than the budget of 1s"))
+
+	buf := &bytes.Buffer{}
+	printPolicyFailures(buf, failures[2:])
+	Expect(buf.String()).To(Equal(
+		"The run failed the following Sweet policies:\n" +
+			"  SlowSuite took 1.5s, which is longer than the budget of 1s (-sweet.suitebudget, exit code 6)\n" +
+			"\n",
+	))
+}
@@ -0,0 +1,179 @@
+package sweet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// quarantineFile lists known flaky tests. It's meant to be checked in so
+// everyone running the tests, including CI, quarantines the same tests.
+type quarantineFile struct {
+	Tests []*quarantineEntry `yaml:"tests"`
+}
+
+type quarantineEntry struct {
+	Package string `yaml:"package"`
+	// Test is the full name of the test, such as "MySuite/TestThing". Any
+	// subtests of the test are quarantined along with it.
+	Test   string `yaml:"test"`
+	Reason string `yaml:"reason,omitempty"`
+	Added  string `yaml:"added,omitempty"`
+}
+
+// quarantinePath resolves the path given with -sweet.quarantine for a test
+// running in dir, so every package can use the same path to find the checked
+// in file.
+func quarantinePath(path string, cfg *config, dir string) string {
+	return projectPath(path, cfg, cfg.Applied("sweet.quarantine"), dir)
+}
+
+// loadQuarantine reads the quarantine file at path. If the file doesn't exist
+// it's an error unless allowMissing is true, in which case it has no tests in
+// it yet.
+func loadQuarantine(path string, allowMissing bool) (*quarantineFile, error) {
+	q := &quarantineFile{}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && allowMissing {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, q)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", path, err)
+	}
+
+	return q, nil
+}
+
+// Contains returns true if the test, or a test it's a subtest of, is in the
+// quarantine file.
+func (q *quarantineFile) Contains(pkg string, test string) bool {
+	if q == nil {
+		return false
+	}
+
+	for _, entry := range q.Tests {
+		if entry.Package != pkg {
+			continue
+		}
+		if entry.Test == test || strings.HasPrefix(test, entry.Test+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addQuarantinedTests adds tests to the quarantine file at path, returning the
+// ones that weren't already in it. The file is locked and read again so
+// packages running at the same time don't lose each other's additions.
+func addQuarantinedTests(path string, pkg string, tests []string, reason string) ([]string, error) {
+	added := make([]string, 0)
+
+	err := withFileLock(path, func() error {
+		q, err := loadQuarantine(path, true)
+		if err != nil {
+			return err
+		}
+
+		for _, test := range tests {
+			if q.Contains(pkg, test) {
+				continue
+			}
+			q.Tests = append(q.Tests, &quarantineEntry{
+				Package: pkg,
+				Test:    test,
+				Reason:  reason,
+				Added:   time.Now().Format("2006-01-02"),
+			})
+			added = append(added, test)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		sort.SliceStable(q.Tests, func(i, j int) bool {
+			if q.Tests[i].Package != q.Tests[j].Package {
+				return q.Tests[i].Package < q.Tests[j].Package
+			}
+			return q.Tests[i].Test < q.Tests[j].Test
+		})
+
+		data, err := yaml.Marshal(q)
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(path, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+func (s *S) isQuarantined(testName *TestName) bool {
+	return s.quarantine.Contains(packageImportPath(), testName.String())
+}
+
+// checkFlakyTests looks for tests in the history that have both passed and
+// failed on the same commit and source. They're added to the quarantine file
+// when -sweet.updatequarantine is used, otherwise they're listed so someone
+// can look into them.
+func (s *S) checkFlakyTests() {
+	if *flagHistory == "" {
+		return
+	}
+
+	pkg := packageImportPath()
+	history, err := LoadHistory(*flagHistory, pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not load the test history from %s: %s\n", *flagHistory, err)
+		return
+	}
+
+	flaky := make([]string, 0)
+	for _, test := range history.Flaky() {
+		if !s.quarantine.Contains(pkg, test) {
+			flaky = append(flaky, test)
+		}
+	}
+	if len(flaky) == 0 {
+		return
+	}
+
+	path := s.quarantinePath
+	if *flagUpdateQuarantine && path != "" {
+		added, err := addQuarantinedTests(path, pkg, flaky, "passed and failed on the same commit")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Could not update the quarantine file %s: %s\n", path, err)
+			return
+		}
+		if len(added) > 0 {
+			fmt.Printf("Added flaky tests to the quarantine file %s: %s\n", path, strings.Join(added, ", "))
+		}
+		return
+	}
+
+	fmt.Printf("Found flaky tests that aren't quarantined: %s\n", strings.Join(flaky, ", "))
+}
+
+// quarantinedMessage describes a quarantined test's failure in a line, for
+// reporters showing it as skipped or ignored.
+func quarantinedMessage(stats *TestFailedStats) string {
+	if onlySubtestsFailed(stats) || stats.Message == "" {
+		return "Quarantined"
+	}
+
+	return "Quarantined: " + firstLine(stats.Message)
+}
@@ -0,0 +1,92 @@
+package sweet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/gomega"
+)
+
+type QuarantineSuite struct{}
+
+func (s *QuarantineSuite) TestContains(t T) {
+	var q *quarantineFile
+	Expect(q.Contains("example.com/pkg", "MySuite/TestFlaky")).To(BeFalse())
+
+	q = &quarantineFile{Tests: []*quarantineEntry{
+		{Package: "example.com/pkg", Test: "MySuite/TestFlaky"},
+	}}
+	Expect(q.Contains("example.com/pkg", "MySuite/TestFlaky")).To(BeTrue())
+	Expect(q.Contains("example.com/pkg", "MySuite/TestFlaky/Sub")).To(BeTrue())
+	Expect(q.Contains("example.com/pkg", "MySuite/TestFlakyOther")).To(BeFalse())
+	Expect(q.Contains("example.com/other", "MySuite/TestFlaky")).To(BeFalse())
+}
+
+func (s *QuarantineSuite) TestAddTests(t T) {
+	dir, err := ioutil.TempDir("", "sweet-quarantine")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "quarantine.yaml")
+
+	_, err = loadQuarantine(path, false)
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	q, err := loadQuarantine(path, true)
+	Expect(err).To(BeNil())
+	Expect(q.Tests).To(BeEmpty())
+
+	added, err := addQuarantinedTests(path, "example.com/second", []string{"S/TestB", "S/TestA"}, "flaky")
+	Expect(err).To(BeNil())
+	Expect(added).To(Equal([]string{"S/TestB", "S/TestA"}))
+
+	added, err = addQuarantinedTests(path, "example.com/first", []string{"S/TestA"}, "flaky")
+	Expect(err).To(BeNil())
+	Expect(added).To(Equal([]string{"S/TestA"}))
+
+	added, err = addQuarantinedTests(path, "example.com/second", []string{"S/TestA", "S/TestA/Sub"}, "flaky")
+	Expect(err).To(BeNil())
+	Expect(added).To(BeEmpty())
+
+	q, err = loadQuarantine(path, false)
+	Expect(err).To(BeNil())
+	names := make([]string, 0)
+	for _, entry := range q.Tests {
+		names = append(names, entry.Package+" "+entry.Test)
+		Expect(entry.Reason).To(Equal("flaky"))
+		Expect(entry.Added).ToNot(BeEmpty())
+	}
+	Expect(names).To(Equal([]string{
+		"example.com/first S/TestA",
+		"example.com/second S/TestA",
+		"example.com/second S/TestB",
+	}))
+}
+
+func (s *QuarantineSuite) TestQuarantinePath(t T) {
+	dir, err := ioutil.TempDir("", "sweet-quarantine")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	pkgDir := filepath.Join(dir, "module", "pkg")
+	Expect(os.MkdirAll(pkgDir, 0755)).To(Succeed())
+	Expect(ioutil.WriteFile(filepath.Join(dir, "module", "go.mod"), []byte("module example.com/module\n"), 0644)).To(Succeed())
+
+	Expect(quarantinePath("", nil, pkgDir)).To(Equal(""))
+	Expect(quarantinePath("/abs/quarantine.yaml", nil, pkgDir)).To(Equal("/abs/quarantine.yaml"))
+
+	// Relative to the module root when given on the command line
+	Expect(quarantinePath("quarantine.yaml", nil, pkgDir)).To(Equal(filepath.Join(dir, "module", "quarantine.yaml")))
+	Expect(quarantinePath("quarantine.yaml", nil, dir)).To(Equal(filepath.Join(dir, "quarantine.yaml")))
+
+	// Relative to the configuration file when set in it
+	cfg := &config{
+		Path:    filepath.Join(dir, "module", "pkg", "sweet.yaml"),
+		applied: map[string]bool{"sweet.quarantine": true},
+	}
+	Expect(quarantinePath("quarantine.yaml", cfg, pkgDir)).To(Equal(filepath.Join(pkgDir, "quarantine.yaml")))
+
+	cfg.applied = map[string]bool{}
+	Expect(quarantinePath("quarantine.yaml", cfg, pkgDir)).To(Equal(filepath.Join(dir, "module", "quarantine.yaml")))
+}
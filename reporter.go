@@ -0,0 +1,96 @@
+package sweet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	reportersLock sync.RWMutex
+	reporters     = map[string]func() Plugin{
+		"default": func() Plugin { return newConsoleReporter(consoleDefault) },
+		"dots":    func() Plugin { return newConsoleReporter(consoleDots) },
+		"verbose": func() Plugin { return newConsoleReporter(consoleVerbose) },
+		"quiet":   func() Plugin { return newConsoleReporter(consoleQuiet) },
+	}
+)
+
+// RegisterReporter makes a reporter available to be selected by name using
+// the -sweet.reporter flag. Reporters are normal plugins, the factory is only
+// called if the reporter is selected.
+//
+// Besides the console reporters, default, dots, verbose and quiet, sweet
+// registers annotations, history, html, json, junit, tap and teamcity. Their
+// New...Reporter constructors create the same reporters for use with
+// RegisterPlugin or SetReporter.
+func RegisterReporter(name string, factory func() Plugin) {
+	reportersLock.Lock()
+	defer reportersLock.Unlock()
+
+	reporters[strings.ToLower(name)] = factory
+}
+
+func reporterNames() []string {
+	reportersLock.RLock()
+	defer reportersLock.RUnlock()
+
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// newReporters creates the reporters for a comma separated list of reporter names.
+func newReporters(names string) ([]Plugin, error) {
+	reportersLock.RLock()
+	defer reportersLock.RUnlock()
+
+	res := make([]Plugin, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		factory, ok := reporters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown reporter \"%s\"", name)
+		}
+		res = append(res, factory())
+	}
+
+	return res, nil
+}
+
+// SetReporter replaces the reporters selected with the -sweet.reporter flag with
+// the ones provided. Passing no reporters disables reporting to the console.
+func (s *S) SetReporter(reporters ...Plugin) {
+	s.reporters = reporters
+	s.reportersSet = true
+}
+
+func (s *S) registerReporters() error {
+	if !s.reportersSet {
+		reporters, err := newReporters(*flagReporter)
+		if err != nil {
+			return err
+		}
+		s.reporters = reporters
+	}
+
+	// Reporters are registered after the rest of the plugins so they can be
+	// replaced during set up, but they should still see events first.
+	userPlugins := s.plugins
+	s.plugins = make([]Plugin, 0, len(s.reporters)+len(userPlugins))
+	for _, reporter := range s.reporters {
+		s.RegisterPlugin(reporter)
+	}
+	s.plugins = append(s.plugins, userPlugins...)
+
+	return nil
+}
@@ -0,0 +1,341 @@
+package sweet
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failedTests is the results file sweet keeps for each package, listing the
+// tests that failed the last time they ran so -sweet.rerunfailed can run
+// only those.
+type failedTests struct {
+	Package string
+	Time    time.Time
+	Failed  []string
+}
+
+// resultsDir returns the directory the results files are saved in, or an
+// empty string when they aren't being saved. Results are only saved when
+// asked for with -sweet.results, or when -sweet.rerunfailed or
+// -sweet.order=failed need them. Unless -sweet.results gives a directory
+// they're kept in the user's cache for the module being tested, so different
+// checkouts of the same module don't share results.
+func resultsDir() string {
+	switch *flagResults {
+	case "none":
+		return ""
+	case "":
+		if !*flagRerunFailed && *flagOrder != orderFailed {
+			return ""
+		}
+	case "cache":
+	default:
+		return *flagResults
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	root := findModuleRoot(wd)
+	if root == "" {
+		root = wd
+	}
+
+	return filepath.Join(cacheDir, "sweet", "results", moduleKey(root))
+}
+
+// moduleKey identifies the module at root by its path on disk.
+func moduleKey(root string) string {
+	sum := sha1.Sum([]byte(root))
+	return filepath.Base(root) + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+func resultsFile(dir string, pkg string) string {
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(pkg, "_")+".json")
+}
+
+// loadFailedTests reads the results file of the package. A package without a
+// results file doesn't have any failed tests.
+func loadFailedTests(dir string, pkg string) (*failedTests, error) {
+	res := &failedTests{
+		Package: pkg,
+		Failed:  make([]string, 0),
+	}
+
+	path := resultsFile(dir, pkg)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return res, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	return res, nil
+}
+
+// loadRerunTests loads the tests to run for -sweet.rerunfailed. When nothing
+// failed last time there's nothing to narrow down so every test is run.
+func loadRerunTests() (*failedTests, error) {
+	dir := resultsDir()
+	if dir == "" {
+		return nil, fmt.Errorf("-sweet.rerunfailed needs the results saved with -sweet.results")
+	}
+
+	results, err := loadFailedTests(dir, packageImportPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Failed) == 0 {
+		return nil, nil
+	}
+
+	return results, nil
+}
+
+// Includes returns true if the test failed, is a parent of a test that
+// failed or is a subtest of a test that failed by itself rather than because
+// of its subtests. A nil list includes every test.
+func (f *failedTests) Includes(testName string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, failed := range f.Failed {
+		if failed == testName || strings.HasPrefix(failed, testName+"/") {
+			return true
+		}
+		if strings.HasPrefix(testName, failed+"/") && !f.hasFailedSubtests(failed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *failedTests) hasFailedSubtests(testName string) bool {
+	for _, failed := range f.Failed {
+		if strings.HasPrefix(failed, testName+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// update replaces the results of the tests that ran with their new results.
+// Tests that didn't run this time, such as ones left out by -sweet.include,
+// keep their previous results.
+func (f *failedTests) update(ran []string, failed []string) {
+	kept := make([]string, 0, len(f.Failed))
+	for _, prev := range f.Failed {
+		replaced := false
+		for _, name := range ran {
+			if prev == name || strings.HasPrefix(prev, name+"/") {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			kept = append(kept, prev)
+		}
+	}
+
+	seen := make(map[string]bool)
+	f.Failed = make([]string, 0, len(kept)+len(failed))
+	for _, name := range append(kept, failed...) {
+		if !seen[name] {
+			seen[name] = true
+			f.Failed = append(f.Failed, name)
+		}
+	}
+	sort.Strings(f.Failed)
+}
+
+// prune drops the results of tests that aren't registered anymore, such as
+// ones that were renamed or deleted, so they don't stay in the results file
+// forever.
+func (f *failedTests) prune(registered map[string]bool) {
+	kept := make([]string, 0, len(f.Failed))
+	for _, name := range f.Failed {
+		if registered[parentTest(name)] {
+			kept = append(kept, name)
+		}
+	}
+	f.Failed = kept
+}
+
+// anyRegistered returns true if any of the failed tests are still registered.
+func (f *failedTests) anyRegistered(registered map[string]bool) bool {
+	for _, name := range f.Failed {
+		if registered[parentTest(name)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parentTest returns the name of the test a result is for, leaving out any
+// subtests.
+func parentTest(name string) string {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return name
+	}
+
+	return parts[0] + "/" + parts[1]
+}
+
+// registeredTests returns the names of the tests in every suite added to
+// sweet, whether or not they're selected to run.
+func (s *S) registeredTests() map[string]bool {
+	tests := make(map[string]bool)
+	for _, runner := range s.suiteRunners {
+		suiteName := runner.name()
+		suiteType := reflect.TypeOf(runner.suite)
+		for idx := 0; idx < suiteType.NumMethod(); idx++ {
+			name := suiteType.Method(idx).Name
+			if strings.HasPrefix(name, "Test") {
+				tests[newTestName(suiteName, []string{name}).String()] = true
+			}
+		}
+	}
+
+	return tests
+}
+
+// selectsTests returns true if only some of the tests are being run because
+// of -sweet.include, -sweet.exclude, -sweet.tags, -sweet.rerunfailed or
+// -test.run.
+func (s *S) selectsTests() bool {
+	if len(flagInclude) > 0 || len(flagExclude) > 0 || len(flagTags) > 0 || s.rerun != nil {
+		return true
+	}
+	if run := flag.Lookup("test.run"); run != nil && run.Value.String() != "" {
+		return true
+	}
+
+	return false
+}
+
+func (s *S) shouldRun(testName *TestName) bool {
+	return s.rerun.Includes(testName.String())
+}
+
+// resultsRecorder is the plugin keeping the results file of the package up
+// to date after each run.
+type resultsRecorder struct {
+	BasePlugin
+
+	dir string
+	pkg string
+
+	// registered are the tests in the test binary, set when only some of
+	// them are run so the results of the ones that are gone can be dropped.
+	// When it's nil every test was run and the results are replaced.
+	registered map[string]bool
+
+	lock   sync.Mutex
+	ran    []string
+	failed []string
+}
+
+func newResultsRecorder(dir string) *resultsRecorder {
+	return &resultsRecorder{
+		dir:    dir,
+		pkg:    packageImportPath(),
+		ran:    make([]string, 0),
+		failed: make([]string, 0),
+	}
+}
+
+func (p *resultsRecorder) Name() string {
+	return "Results Recorder"
+}
+
+func (p *resultsRecorder) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.addResult(testName, false)
+}
+func (p *resultsRecorder) TestFailed(testName *TestName, stats *TestFailedStats) {
+	p.addResult(testName, true)
+}
+func (p *resultsRecorder) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.addResult(testName, false)
+}
+
+func (p *resultsRecorder) addResult(testName *TestName, failed bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Only the tests themselves replace previous results, so a subtest that
+	// wasn't run this time doesn't leave a stale failure behind.
+	if !testName.IsSubtest() {
+		p.ran = append(p.ran, testName.String())
+	}
+	if failed {
+		p.failed = append(p.failed, testName.String())
+	}
+}
+
+func (p *resultsRecorder) Finished() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	err := p.save()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Could not save the test results to %s: %s\n", p.dir, err)
+	}
+}
+
+func (p *resultsRecorder) save() error {
+	path := resultsFile(p.dir, p.pkg)
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return err
+	}
+
+	return withFileLock(path, func() error {
+		results, err := loadFailedTests(p.dir, p.pkg)
+		if err != nil {
+			// A broken results file is replaced rather than blocking every
+			// run after it.
+			results = &failedTests{Package: p.pkg}
+		}
+
+		results.Time = time.Now()
+		if p.registered == nil {
+			results.Failed = make([]string, 0)
+		} else {
+			results.prune(p.registered)
+		}
+		results.update(p.ran, p.failed)
+
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(path, data)
+	})
+}
@@ -0,0 +1,146 @@
+package sweet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/gomega"
+)
+
+type RerunSuite struct{}
+
+func (s *RerunSuite) TestIncludes(t T) {
+	failed := &failedTests{
+		Failed: []string{
+			"MySuite/TestFailed",
+			"MySuite/TestSubtests",
+			"MySuite/TestSubtests/Failed",
+		},
+	}
+
+	Expect(failed.Includes("MySuite")).To(BeTrue())
+	Expect(failed.Includes("MySuite/TestFailed")).To(BeTrue())
+	Expect(failed.Includes("MySuite/TestFailed/Sub")).To(BeTrue())
+	Expect(failed.Includes("MySuite/TestSubtests")).To(BeTrue())
+	Expect(failed.Includes("MySuite/TestSubtests/Failed")).To(BeTrue())
+	Expect(failed.Includes("MySuite/TestSubtests/Passed")).To(BeFalse())
+	Expect(failed.Includes("MySuite/TestPassed")).To(BeFalse())
+	Expect(failed.Includes("MySuite/TestFailedToo")).To(BeFalse())
+	Expect(failed.Includes("OtherSuite")).To(BeFalse())
+
+	var all *failedTests
+	Expect(all.Includes("OtherSuite/TestPassed")).To(BeTrue())
+}
+
+func (s *RerunSuite) TestUpdate(t T) {
+	failed := &failedTests{
+		Failed: []string{
+			"MySuite/TestFixed",
+			"MySuite/TestFixed/Sub",
+			"MySuite/TestNotRun",
+			"MySuite/TestStillFailing",
+		},
+	}
+
+	failed.update(
+		[]string{"MySuite/TestFixed", "MySuite/TestStillFailing", "MySuite/TestNew"},
+		[]string{"MySuite/TestStillFailing", "MySuite/TestNew"},
+	)
+
+	Expect(failed.Failed).To(Equal([]string{
+		"MySuite/TestNew",
+		"MySuite/TestNotRun",
+		"MySuite/TestStillFailing",
+	}))
+}
+
+func (s *RerunSuite) TestPrune(t T) {
+	failed := &failedTests{
+		Failed: []string{
+			"GoneSuite/TestFailed",
+			"MySuite/TestFailed",
+			"MySuite/TestFailed/Sub",
+			"MySuite/TestRenamed",
+		},
+	}
+	registered := map[string]bool{"MySuite/TestFailed": true}
+
+	Expect(failed.anyRegistered(registered)).To(BeTrue())
+	failed.prune(registered)
+	Expect(failed.Failed).To(Equal([]string{
+		"MySuite/TestFailed",
+		"MySuite/TestFailed/Sub",
+	}))
+	Expect(failed.anyRegistered(map[string]bool{"MySuite/TestOther": true})).To(BeFalse())
+}
+
+func (s *RerunSuite) TestRecorder(t T) {
+	dir, err := ioutil.TempDir("", "sweet-results")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	p := newResultsRecorder(dir)
+	p.TestPassed(newTestName("MySuite", []string{"TestPassed"}), &TestPassedStats{})
+	p.TestFailed(
+		newSubtestName(newTestName("MySuite", []string{"TestFailed"}), "Sub"),
+		&TestFailedStats{},
+	)
+	p.TestFailed(newTestName("MySuite", []string{"TestFailed"}), &TestFailedStats{})
+	p.Finished()
+
+	results, err := loadFailedTests(dir, packageImportPath())
+	Expect(err).To(BeNil())
+	Expect(results.Package).To(Equal(packageImportPath()))
+	Expect(results.Failed).To(Equal([]string{
+		"MySuite/TestFailed",
+		"MySuite/TestFailed/Sub",
+	}))
+
+	// Running every test replaces the results, even of tests that didn't
+	// run this time since they don't exist anymore
+	p = newResultsRecorder(dir)
+	p.TestPassed(newTestName("MySuite", []string{"TestRenamed"}), &TestPassedStats{})
+	p.Finished()
+
+	results, err = loadFailedTests(dir, packageImportPath())
+	Expect(err).To(BeNil())
+	Expect(results.Failed).To(BeEmpty())
+
+	missing, err := loadFailedTests(dir, "example.com/missing")
+	Expect(err).To(BeNil())
+	Expect(missing.Failed).To(BeEmpty())
+}
+
+func (s *RerunSuite) TestResultsDir(t T) {
+	defer func(prev string) { *flagResults = prev }(*flagResults)
+	defer func(prev bool) { *flagRerunFailed = prev }(*flagRerunFailed)
+
+	*flagResults = "none"
+	*flagRerunFailed = true
+	Expect(resultsDir()).To(BeEmpty())
+
+	*flagResults = "/tmp/results"
+	Expect(resultsDir()).To(Equal("/tmp/results"))
+
+	// Nothing is saved unless it's asked for or needed
+	*flagResults = ""
+	*flagRerunFailed = false
+	Expect(resultsDir()).To(BeEmpty())
+
+	*flagRerunFailed = true
+	dir := resultsDir()
+
+	*flagResults = "cache"
+	*flagRerunFailed = false
+	Expect(resultsDir()).To(Equal(dir))
+	if dir != "" {
+		wd, err := os.Getwd()
+		Expect(err).To(BeNil())
+		Expect(filepath.Base(dir)).To(Equal(moduleKey(findModuleRoot(wd))))
+	}
+
+	// Checkouts of the same module in different places are kept apart
+	Expect(moduleKey("/src/a/sweet")).To(HavePrefix("sweet-"))
+	Expect(moduleKey("/src/a/sweet")).ToNot(Equal(moduleKey("/src/b/sweet")))
+}
@@ -26,6 +26,8 @@ func hasParams(method reflect.Method, paramTypes []reflect.Type) bool {
 type registeredOptions struct {
 	Plugin  Plugin
 	Options *PluginOptions
+	// Values are the option values the plugin was given.
+	Values map[string]string
 }
 
 type S struct {
@@ -34,6 +36,20 @@ type S struct {
 
 	plugins []Plugin
 	options map[string]*registeredOptions
+
+	config         *config
+	quarantine     *quarantineFile
+	quarantinePath string
+	rerun          *failedTests
+	lastResults    *failedTests
+
+	// stats are kept for every run to check the policies at the end.
+	stats *statsPlugin
+
+	reporters    []Plugin
+	reportersSet bool
+
+	bus *eventBus
 }
 
 func Run(m *testing.M, f func(s *S)) {
@@ -41,29 +57,166 @@ func Run(m *testing.M, f func(s *S)) {
 		flag.Parse()
 	}
 
+	cfg, err := loadProjectConfig()
+	if err == nil && cfg != nil {
+		err = cfg.Apply(flag.CommandLine)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while loading the Sweet configuration file: %s\n", err)
+		os.Exit(1)
+	}
+
 	s := &S{
 		suiteRunners:     make([]*suiteRunner, 0),
 		deprecatedSuites: make(map[interface{}]bool),
 
 		plugins: make([]Plugin, 0),
 		options: make(map[string]*registeredOptions),
+
+		config: cfg,
+	}
+
+	if *flagQuarantine != "" {
+		wd, err := os.Getwd()
+		if err == nil {
+			s.quarantinePath = quarantinePath(*flagQuarantine, cfg, wd)
+			// A missing file is fine when it's about to be created
+			s.quarantine, err = loadQuarantine(s.quarantinePath, *flagUpdateQuarantine)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"Error while loading the quarantine file: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *flagRerunFailed {
+		s.rerun, err = loadRerunTests()
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"Error while loading the failed tests: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	err = checkOrder(*flagOrder)
+	if err == nil && *flagOrder == orderFailed {
+		if dir := resultsDir(); dir != "" {
+			s.lastResults, err = loadFailedTests(dir, packageImportPath())
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while setting up the test order: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = checkTagExprs(flagTags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while parsing the tags: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = checkListFormat(*flagList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while setting up the test list: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, hide := range flagHide {
+		addHiddenPackages(strings.Split(hide, ",")...)
 	}
-	s.RegisterPlugin(newStatsPlugin())
 
 	f(s)
 
+	err = s.checkDependencies()
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while checking test dependencies: %s\n", err)
+		os.Exit(1)
+	}
+
+	if s.rerun != nil && !s.rerun.anyRegistered(s.registeredTests()) {
+		fmt.Fprintf(os.Stderr,
+			"Error while loading the failed tests: none of the tests that failed last time exist anymore (%s),\n"+
+				"run without -sweet.rerunfailed to update the results\n",
+			strings.Join(s.rerun.Failed, ", "))
+		os.Exit(1)
+	}
+
+	err = s.registerReporters()
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Error while setting up reporters: %s\n"+
+				"Available reporters are: %s\n",
+			err, strings.Join(reporterNames(), ", "))
+		os.Exit(1)
+	}
+
+	if *flagHistory != "" {
+		s.plugins = append(s.plugins, newHistoryRecorder(*flagHistory))
+	}
+	if dir := resultsDir(); dir != "" {
+		recorder := newResultsRecorder(dir)
+		if s.selectsTests() {
+			recorder.registered = s.registeredTests()
+		}
+		s.plugins = append(s.plugins, recorder)
+	}
+	s.stats = newStatsPlugin()
+	s.plugins = append(s.plugins, s.stats)
+
+	err = s.checkFlagOpts()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while setting up plugins: %s\n", err)
+		os.Exit(1)
+	}
+
 	if *flagHelp {
 		fmt.Println("Sweet Options")
 		fmt.Println("=============")
 
 		fmt.Println("-sweet.help: Displays this help text")
+		fmt.Println("-sweet.config: Path of the configuration file to use, or \"none\" to not use one")
 		fmt.Println("-sweet.opt: Passes an argument to a sweet plugin.")
 		fmt.Println("            Ex: -sweet.opt \"plug.myopt=myval\"")
 		fmt.Println("-sweet.include: Only run tests that match the provided expression")
 		fmt.Println("-sweet.exclude: Do not include tests that match the provided expression")
+		fmt.Println("-sweet.tags: Only run tests with tags matching the expression, can be used more than once")
+		fmt.Println("             Ex: -sweet.tags \"integration,!slow\"")
+		fmt.Println("-sweet.reporter: Reporters to use for results, separated by commas")
+		fmt.Printf("                 Available: %s\n", strings.Join(reporterNames(), ", "))
 		fmt.Println("-sweet.extended: Show extended error information for failed tests")
+		fmt.Println("-sweet.snippet: Show this many lines of source around failure lines")
+		fmt.Println("-sweet.fullpaths: Show failure paths relative to the package directory")
+		fmt.Println("-sweet.hide: Hide failure frames from the provided packages")
+		fmt.Println("             Ex: -sweet.hide \"github.com/me/asserts\"")
+		fmt.Println("-sweet.slowest: List this many of the slowest tests after the results")
+		fmt.Println("-sweet.budget: Fail any test that takes longer than this duration to run")
+		fmt.Println("               Ex: -sweet.budget 5s")
+		fmt.Println("-sweet.history: Save the results of each run to this directory")
+		fmt.Println("-sweet.quarantine: File listing flaky tests that don't fail the run")
+		fmt.Println("                   Relative paths are from the root of the repository")
+		fmt.Println("-sweet.updatequarantine: Add flaky tests found in the history to the quarantine file")
+		fmt.Println("-sweet.results: Save the failed tests of each package to this directory")
+		fmt.Println("                \"cache\" saves them in the user cache directory, which is also used when")
+		fmt.Println("                -sweet.rerunfailed or -sweet.order=failed is given, and \"none\" doesn't save them")
+		fmt.Println("-sweet.rerunfailed: Only run the tests that failed the last time they ran")
+		fmt.Println("-sweet.order: Order to run suites and tests in")
+		fmt.Printf("              Available: %s\n", strings.Join(orderNames, ", "))
+		fmt.Println("-sweet.failempty: Fail the run if no tests were run")
+		fmt.Println("-sweet.maxskips: Fail the run if more than this percentage of tests were skipped")
+		fmt.Println("-sweet.faildeprecated: Fail the run if any test methods use a deprecated signature")
+		fmt.Println("-sweet.suitebudget: Fail the run if any suite takes longer than this duration to run")
+		fmt.Println("-sweet.list: List the suites and tests that would be run instead of running them")
+		fmt.Printf("             Available: %s\n", strings.Join(listFormats, ", "))
 		fmt.Println("")
 
+		s.printEffectiveConfig()
+
 		sortedPrefixes := make([]string, 0)
 		for prefix := range s.options {
 			sortedPrefixes = append(sortedPrefixes, prefix)
@@ -73,8 +226,25 @@ func Run(m *testing.M, f func(s *S)) {
 		for _, prefix := range sortedPrefixes {
 			opts := s.options[prefix]
 
-			for optionName, optSetting := range opts.Options.Options {
-				fmt.Printf("  %s.%s - %s\n", prefix, optionName, optSetting.Help)
+			sortedNames := make([]string, 0, len(opts.Options.Options))
+			for optionName := range opts.Options.Options {
+				sortedNames = append(sortedNames, optionName)
+			}
+			sort.Strings(sortedNames)
+
+			for _, optionName := range sortedNames {
+				optSetting := opts.Options.Options[optionName]
+				fmt.Printf("  %s.%s (%s) - %s\n", prefix, optionName, optSetting.Type, optSetting.Help)
+				if optSetting.Type == OptionEnum {
+					fmt.Printf("      Values: %s\n", strings.Join(optSetting.Values, ", "))
+				}
+				if optSetting.Default != "" {
+					fmt.Printf("      Default: %s\n", optSetting.Default)
+				}
+				fmt.Printf("      Environment: %s\n", optionEnvName(prefix, optionName))
+				if value, ok := opts.Values[optionName]; ok {
+					fmt.Printf("      Current: %s\n", value)
+				}
 			}
 		}
 
@@ -83,6 +253,20 @@ func Run(m *testing.M, f func(s *S)) {
 		os.Exit(0)
 	}
 
+	if *flagOrder == orderFailed {
+		s.suiteRunners = orderFailedFirst(s.suiteRunners, s.lastResults)
+	}
+
+	if *flagList != "" {
+		err = s.printTests(os.Stdout, *flagList)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"Error while listing tests: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	newM, err := mainStart(s)
 	if err == errUnsupportedVersion {
 		fmt.Fprintf(os.Stderr,
@@ -96,11 +280,14 @@ func Run(m *testing.M, f func(s *S)) {
 		os.Exit(1)
 	}
 
+	s.bus = newEventBus(s.plugins)
+	s.emitStarting()
+
 	code := newM.Run()
 
-	for _, plugin := range s.plugins {
-		plugin.Finished()
-	}
+	s.emitFinished()
+
+	s.checkFlakyTests()
 
 	const deprecationExampleLength = 3
 	deprecatedUsages := make([]string, 0)
@@ -144,6 +331,16 @@ func Run(m *testing.M, f func(s *S)) {
 		fmt.Fprintf(os.Stderr, "\n")
 	}
 
+	policyFailures := checkPolicies(s.stats.Suites(), deprecatedUsages)
+	if len(policyFailures) > 0 {
+		printPolicyFailures(os.Stderr, policyFailures)
+
+		// Failed tests keep their usual exit code
+		if code == 0 {
+			code = policyFailures[0].Code
+		}
+	}
+
 	os.Exit(code)
 }
 
@@ -158,12 +355,33 @@ func (s *S) TearDownAllTests(f func(t T)) func(t T) {
 	return f
 }
 
+// HidePackages hides failure frames from the given packages, and any packages
+// under them, so failures point at the test code calling into them instead.
+// This is useful for assertion helpers wrapping a matcher library.
+func (s *S) HidePackages(packages ...string) {
+	addHiddenPackages(packages...)
+}
+
 func (s *S) RegisterPlugin(plugin Plugin) {
 	if plugin == nil {
 		return
 	}
 
-	plugOpts := plugin.Options()
+	if versioned, ok := plugin.(VersionedPlugin); ok {
+		if versioned.PluginAPIVersion() > PluginAPIVersion {
+			fmt.Fprintf(os.Stderr,
+				"ERROR: Sweet plugin %s requires plugin API version %d but this version\n"+
+					"of Sweet only provides version %d. Please update Sweet to use it.\n",
+				plugin.Name(), versioned.PluginAPIVersion(), PluginAPIVersion)
+			os.Exit(1)
+		}
+	}
+
+	var plugOpts *PluginOptions
+	optsPlugin, hasOpts := plugin.(OptionsPlugin)
+	if hasOpts {
+		plugOpts = optsPlugin.Options()
+	}
 	if plugOpts != nil {
 		if oldOpts, ok := s.options[plugOpts.Prefix]; ok {
 			fmt.Fprintf(os.Stderr,
@@ -180,29 +398,100 @@ func (s *S) RegisterPlugin(plugin Plugin) {
 			Options: plugOpts,
 		}
 
-		// When registering a plugin, go through each option and see if it matches
-		// a plugin option.  If it does, set the option in the plugin
-
-		for _, opt := range flagOpts {
-			valIdx := strings.Index(opt, "=")
-			value := ""
-			if valIdx >= 0 {
-				value = opt[valIdx+1:]
-			}
-			name := opt[:valIdx]
+		// When registering a plugin, work out the value of each of its options
+		// from the command line, environment and defaults and set them.
+		// Relative paths are resolved the same way as the quarantine file
+		// so every package in the module writes to the same place.
+		flagValues, err := parseFlagOpts(flagOpts)
+		var wd string
+		if err == nil {
+			wd, err = os.Getwd()
+		}
+		if err == nil {
+			var values map[string]string
+			values, err = resolvePluginOptions(
+				plugOpts,
+				flagValues[plugOpts.Prefix],
+				s.config.PluginValues(plugOpts.Prefix),
+				os.LookupEnv,
+				func(path string, fromConfig bool) string {
+					return projectPath(path, s.config, fromConfig, wd)
+				},
+			)
+			if err == nil {
+				s.options[plugOpts.Prefix].Values = values
+
+				names := make([]string, 0, len(values))
+				for name := range values {
+					names = append(names, name)
+				}
+				sort.Strings(names)
 
-			if strings.HasPrefix(name, plugOpts.Prefix+".") {
-				plugName := name[len(plugOpts.Prefix+"."):]
-				plugin.SetOption(plugName, value)
+				for _, name := range names {
+					optsPlugin.SetOption(name, values[name])
+				}
 			}
 		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"ERROR: Could not set options for Sweet plugin %s: %s\n",
+				plugin.Name(), err)
+			os.Exit(1)
+		}
 	}
 
 	s.plugins = append(s.plugins, plugin)
 }
 
-func (s *S) AddSuite(suite interface{}) {
-	s.suiteRunners = append(s.suiteRunners, newSuiteRunner(s, suite))
+func (s *S) printEffectiveConfig() {
+	fmt.Println("Effective Configuration")
+	fmt.Println("=======================")
+
+	if s.config != nil {
+		fmt.Printf("Configuration file: %s\n", s.config.Path)
+	} else {
+		fmt.Println("Configuration file: none")
+	}
+
+	flag.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "sweet.") || f.Name == "sweet.help" {
+			return
+		}
+		fmt.Printf("-%s=%s\n", f.Name, f.Value.String())
+	})
+	if timeoutFlag := flag.Lookup("test.timeout"); timeoutFlag != nil {
+		fmt.Printf("-test.timeout=%s\n", timeoutFlag.Value.String())
+	}
+
+	fmt.Println("")
+}
+
+// checkFlagOpts makes sure every option provided with -sweet.opt is for a
+// plugin that was registered.
+func (s *S) checkFlagOpts() error {
+	flagValues, err := parseFlagOpts(flagOpts)
+	if err != nil {
+		return err
+	}
+
+	for prefix := range flagValues {
+		if _, ok := s.options[prefix]; !ok {
+			return fmt.Errorf("no plugin has registered the option prefix \"%s\"", prefix)
+		}
+	}
+
+	return nil
+}
+
+// AddSuite adds a suite of tests to run, along with any options changing how
+// the suite is run.
+func (s *S) AddSuite(suite interface{}, opts ...SuiteOption) {
+	runner := newSuiteRunner(s, suite)
+	for _, opt := range opts {
+		opt(runner)
+	}
+
+	s.suiteRunners = append(s.suiteRunners, runner)
 }
 
 func (s *S) suppressDeprecation(suite interface{}) {
@@ -1,6 +1,8 @@
 package sweet
 
 import (
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"syscall"
 
@@ -45,3 +47,195 @@ func (s *RunnerSuite) TestGomegaFailInGoroutines(t T) {
 	}()
 	<-ch*/
 }
+
+func (s *RunnerSuite) TestRecoverPanics(t T) {
+	code, stdout, _, err := runSubTests("panics", "recovery")
+	Expect(code).To(Equal(1))
+	Expect(err).To(BeNil())
+
+	Expect(stdout).To(ContainSubstring("{Panicked:PanicSuite/TestIndexOutOfRange}\n"))
+	Expect(stdout).To(ContainSubstring("{Panicked:PanicSuite/TestNilDereference}\n"))
+	Expect(stdout).To(ContainSubstring("{Panicked:PanicSuite/TestSubtestPanic/Sub}\n"))
+	Expect(stdout).To(ContainSubstring("{Passed:PanicSuite/TestPasses}\n"))
+
+	// Tear downs should still run for the tests that panicked
+	Expect(stdout).To(ContainSubstring("{TearDownTest:PanicSuite/TestIndexOutOfRange}\n"))
+	Expect(stdout).To(ContainSubstring("{TearDownTest:PanicSuite/TestNilDereference}\n"))
+
+	// The failure should point at the line in the test that panicked
+	Expect(stdout).To(ContainSubstring("panic: runtime error: invalid memory address or nil pointer dereference"))
+	Expect(stdout).To(ContainSubstring("test_test.go:52\n"))
+}
+
+func (s *RunnerSuite) TestSubtestEvents(t T) {
+	code, stdout, _, err := runSubTests("plugins", "subtestevents")
+	Expect(code).To(Equal(1))
+	Expect(err).To(BeNil())
+
+	// Plugins should know the run is starting before any suites start
+	Expect(stdout).To(ContainSubstring("{RunStarting}\n{SuiteStarting:EventSuite}\n"))
+
+	Expect(stdout).To(ContainSubstring("{Starting:EventSuite/TestSubtests:<nil>}\n"))
+	Expect(stdout).To(ContainSubstring("{Starting:EventSuite/TestSubtests/Passes:EventSuite/TestSubtests}\n"))
+	Expect(stdout).To(ContainSubstring("{Passed:EventSuite/TestSubtests/Passes/Nested:EventSuite/TestSubtests/Passes}\n"))
+	Expect(stdout).To(ContainSubstring("{Passed:EventSuite/TestSubtests/Passes:EventSuite/TestSubtests}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:EventSuite/TestSubtests/Fails:EventSuite/TestSubtests:0}\n"))
+	Expect(stdout).To(ContainSubstring("{Skipped:EventSuite/TestSubtests/Skips:EventSuite/TestSubtests}\n"))
+
+	// A failing subtest fails its parent but doesn't stop it
+	Expect(stdout).To(ContainSubstring("{AfterSubtests}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:EventSuite/TestSubtests:<nil>:1}\n"))
+
+	// Parallel subtests only run after their parent returns, the parent is
+	// reported once they've finished
+	Expect(stdout).To(ContainSubstring("{Passed:EventSuite/TestParallelSubtests/Passes:EventSuite/TestParallelSubtests}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:EventSuite/TestParallelSubtests/Fails:EventSuite/TestParallelSubtests:0}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:EventSuite/TestParallelSubtests:<nil>:1}\n"))
+}
+
+func (s *RunnerSuite) TestDurationBudget(t T) {
+	code, stdout, _, err := runSubTests("timing", "budget")
+	Expect(code).To(Equal(1))
+	Expect(err).To(BeNil())
+
+	Expect(stdout).To(ContainSubstring("{Passed:BudgetSuite/TestFast}\n"))
+	Expect(stdout).To(MatchRegexp(`\{Failed:BudgetSuite/TestSlow:Test took \S+, which is longer than the budget of 50ms\}`))
+
+	// Only the test counts against the budget, not each of its subtests
+	Expect(stdout).To(ContainSubstring("{Passed:BudgetSuite/TestSlowSubtests/Sub}\n"))
+	Expect(stdout).To(MatchRegexp(`\{Failed:BudgetSuite/TestSlowSubtests:Test took \S+, which is longer than the budget of 50ms\}`))
+}
+
+func (s *RunnerSuite) TestConfigTimeout(t T) {
+	// go test passes its default -timeout, which the configuration file's
+	// timeout replaces
+	code, stdout, _, err := runSubTests("timing", "timeout")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(1))
+
+	Expect(stdout).To(ContainSubstring("panic: test timed out after 1s"))
+}
+
+func (s *RunnerSuite) TestQuarantine(t T) {
+	code, stdout, _, err := runSubTests("quarantine", "flaky")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(0))
+
+	Expect(stdout).To(ContainSubstring("{Quarantined:FlakySuite/TestFlaky}\n"))
+	Expect(stdout).To(ContainSubstring("{Quarantined:FlakySuite/TestFlaky/Sub}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:FlakySuite/TestFlaky:true}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:FlakySuite/TestFlaky/Sub:true}\n"))
+	Expect(stdout).ToNot(ContainSubstring("TestPasses"))
+}
+
+func (s *RunnerSuite) TestRerunFailed(t T) {
+	code, stdout, _, err := runSubTests("rerun", "failed")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(0))
+
+	Expect(stdout).To(ContainSubstring("{Ran:FailedSuite/TestFailed}\n"))
+	Expect(stdout).To(ContainSubstring("{Ran:FailedSuite/TestSubtests}\n"))
+	Expect(stdout).To(ContainSubstring("{Ran:FailedSuite/TestSubtests/Failed}\n"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:FailedSuite/TestPassed}"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:FailedSuite/TestSubtests/Passed}"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:PassedSuite/TestPassed}"))
+}
+
+func (s *RunnerSuite) TestRerunRenamed(t T) {
+	dir, err := ioutil.TempDir("", "sweet-renamed")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	run := func(name string) (int, string) {
+		code, stdout, _, err := runSubTestsEnv(
+			[]string{"RENAMED_RUN=" + name, "RENAMED_RESULTS=" + dir},
+			"rerun", "renamed",
+		)
+		Expect(err).To(BeNil())
+		return code, stdout
+	}
+	failed := func() []string {
+		results, err := loadFailedTests(dir, "github.com/aphistic/sweet/subtests/rerun/renamed")
+		Expect(err).To(BeNil())
+		return results.Failed
+	}
+
+	code, _ := run("before")
+	Expect(code).To(Equal(1))
+	Expect(failed()).To(Equal([]string{"OldSuite/TestFails"}))
+
+	// The failed test is gone so rerunning it would run nothing
+	code, stdout := run("rerun")
+	Expect(code).To(Equal(1))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:"))
+	Expect(stdout).To(ContainSubstring("none of the tests that failed last time exist anymore (OldSuite/TestFails)"))
+
+	// Only running some of the tests still drops the results of tests that
+	// don't exist anymore
+	code, _ = run("include")
+	Expect(code).To(Equal(0))
+	Expect(failed()).To(BeEmpty())
+
+	code, stdout = run("rerun")
+	Expect(code).To(Equal(0))
+	Expect(stdout).To(ContainSubstring("{Ran:NewSuite/TestPasses}\n"))
+}
+
+func (s *RunnerSuite) TestFailEmptyRun(t T) {
+	code, stdout, _, err := runSubTests("policy", "empty")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(1))
+
+	Expect(stdout).To(ContainSubstring(
+		"No tests were run, check the include and exclude filters (-sweet.failempty, exit code 3)\n",
+	))
+}
+
+func (s *RunnerSuite) TestList(t T) {
+	code, stdout, _, err := runSubTests("list", "dryrun")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(0))
+
+	Expect(stdout).To(HavePrefix(
+		"FirstSuite\n" +
+			"    TestOne\n" +
+			"    TestTwo\n" +
+			"SecondSuite\n" +
+			"    TestThree\n",
+	))
+	Expect(stdout).ToNot(ContainSubstring("{SetUpSuite}"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:"))
+}
+
+func (s *RunnerSuite) TestDependencies(t T) {
+	code, stdout, _, err := runSubTests("deps", "scenario")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(1))
+
+	Expect(stdout).To(ContainSubstring(
+		"{Ran:ScenarioSuite/TestLogin}\n" +
+			"{Ran:ScenarioSuite/TestAddToCart}\n",
+	))
+	Expect(stdout).To(ContainSubstring("{Passed:ScenarioSuite/TestLogin}\n"))
+	Expect(stdout).To(ContainSubstring("{Failed:ScenarioSuite/TestAddToCart}\n"))
+	Expect(stdout).To(ContainSubstring(
+		"{Skipped:ScenarioSuite/TestCheckout:dependency failed: ScenarioSuite/TestAddToCart}\n",
+	))
+	Expect(stdout).To(ContainSubstring(
+		"{Skipped:ScenarioSuite/TestReceipt:dependency skipped: ScenarioSuite/TestCheckout}\n",
+	))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:ScenarioSuite/TestCheckout}"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:ScenarioSuite/TestReceipt}"))
+}
+
+func (s *RunnerSuite) TestTags(t T) {
+	code, stdout, _, err := runSubTests("tags", "selection")
+	Expect(err).To(BeNil())
+	Expect(code).To(Equal(0))
+
+	Expect(stdout).To(ContainSubstring("{Passed:IntegrationSuite/TestFast/Sub:integration}\n"))
+	Expect(stdout).To(ContainSubstring("{Passed:IntegrationSuite/TestFast:integration}\n"))
+	Expect(stdout).To(ContainSubstring("{SuiteFinished:IntegrationSuite:integration}\n"))
+	Expect(stdout).ToNot(ContainSubstring("{Ran:"))
+	Expect(stdout).ToNot(ContainSubstring("UnitSuite"))
+}
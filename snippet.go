@@ -0,0 +1,111 @@
+package sweet
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// SourceSnippet holds the lines of source code surrounding a failure frame.
+type SourceSnippet struct {
+	// StartLine is the line number of the first entry in Lines.
+	StartLine int
+	// Line is the line number the failure happened on.
+	Line  int
+	Lines []string
+}
+
+func loadSnippet(file string, line int, context int) *SourceSnippet {
+	if file == "" || line <= 0 || context < 0 {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	snippet := &SourceSnippet{
+		StartLine: line - context,
+		Line:      line,
+		Lines:     make([]string, 0, context*2+1),
+	}
+	if snippet.StartLine < 1 {
+		snippet.StartLine = 1
+	}
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum < snippet.StartLine {
+			continue
+		}
+		if lineNum > line+context {
+			break
+		}
+
+		snippet.Lines = append(snippet.Lines, scanner.Text())
+	}
+	if scanner.Err() != nil || lineNum < line {
+		// Either we couldn't read the file or it's changed since the test
+		// binary was built, either way the snippet would be wrong.
+		return nil
+	}
+
+	return snippet
+}
+
+// Format returns the snippet with line numbers and a marker on the failing line,
+// which is also colored when highlight is true.
+func (ss *SourceSnippet) Format(highlight bool) string {
+	endLine := ss.StartLine + len(ss.Lines) - 1
+	numWidth := len(fmt.Sprintf("%d", endLine))
+
+	failColor := ansi.ColorFunc("red+b")
+
+	var out strings.Builder
+	for idx, text := range ss.Lines {
+		lineNum := ss.StartLine + idx
+
+		marker := " "
+		if lineNum == ss.Line {
+			marker = ">"
+		}
+
+		formatted := fmt.Sprintf("%s %*d | %s", marker, numWidth, lineNum, text)
+		if highlight && lineNum == ss.Line {
+			formatted = failColor(formatted)
+		}
+
+		out.WriteString(formatted)
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// displayPath returns the path of a file the way it should be shown in failure
+// output. By default this is just the file name but if full paths were asked
+// for it's relative to the working directory so editors can link to it.
+func displayPath(file string) string {
+	if !*flagFullPaths {
+		return filepath.Base(file)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(wd, file)
+	if err != nil {
+		return file
+	}
+
+	return rel
+}
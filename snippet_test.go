@@ -0,0 +1,64 @@
+package sweet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/gomega"
+)
+
+type SnippetSuite struct{}
+
+func (s *SnippetSuite) writeSource(t T) string {
+	dir, err := ioutil.TempDir("", "sweet-snippet")
+	Expect(err).To(BeNil())
+
+	file := filepath.Join(dir, "source.go")
+	err = ioutil.WriteFile(file, []byte("line 1\nline 2\nline 3\nline 4\nline 5\n"), 0644)
+	Expect(err).To(BeNil())
+
+	return file
+}
+
+func (s *SnippetSuite) TestLoadSnippet(t T) {
+	file := s.writeSource(t)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	snippet := loadSnippet(file, 3, 1)
+	Expect(snippet).ToNot(BeNil())
+	Expect(snippet.StartLine).To(Equal(2))
+	Expect(snippet.Line).To(Equal(3))
+	Expect(snippet.Lines).To(Equal([]string{"line 2", "line 3", "line 4"}))
+}
+
+func (s *SnippetSuite) TestLoadSnippetBounds(t T) {
+	file := s.writeSource(t)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	snippet := loadSnippet(file, 1, 2)
+	Expect(snippet.StartLine).To(Equal(1))
+	Expect(snippet.Lines).To(Equal([]string{"line 1", "line 2", "line 3"}))
+
+	snippet = loadSnippet(file, 5, 2)
+	Expect(snippet.StartLine).To(Equal(3))
+	Expect(snippet.Lines).To(Equal([]string{"line 3", "line 4", "line 5"}))
+
+	// The file is shorter than the failure line so it's changed
+	Expect(loadSnippet(file, 10, 2)).To(BeNil())
+	Expect(loadSnippet(filepath.Join(filepath.Dir(file), "missing.go"), 1, 2)).To(BeNil())
+}
+
+func (s *SnippetSuite) TestFormat(t T) {
+	snippet := &SourceSnippet{
+		StartLine: 9,
+		Line:      10,
+		Lines:     []string{"before", "failed", "after"},
+	}
+
+	Expect(snippet.Format(false)).To(Equal(
+		"   9 | before\n" +
+			"> 10 | failed\n" +
+			"  11 | after\n",
+	))
+}
@@ -1,26 +1,37 @@
 package sweet
 
 import (
-	"fmt"
-	"os"
 	"sort"
 	"sync"
 	"sync/atomic"
-
-	"github.com/mgutz/ansi"
-	"golang.org/x/crypto/ssh/terminal"
+	"time"
 )
 
 type statsPlugin struct {
+	BasePlugin
+
 	suitesLock sync.Mutex
 	suites     map[string]*suiteStats
+
+	timesLock sync.Mutex
+	testTimes []*testTime
+	runStart  time.Time
+	runTime   time.Duration
 }
 
 type suiteStats struct {
-	Name    string
-	Passed  int64
-	Failed  int64
-	Skipped int64
+	Name        string
+	Passed      int64
+	Failed      int64
+	Skipped     int64
+	Quarantined int64
+	Time        time.Duration
+}
+
+// testTime is how long a test took to run.
+type testTime struct {
+	Name *TestName
+	Time time.Duration
 }
 
 func newStatsPlugin() *statsPlugin {
@@ -33,88 +44,129 @@ func (p *statsPlugin) Name() string {
 	return "Test Stats"
 }
 
-func (p *statsPlugin) Options() *PluginOptions {
-	return nil
-}
-
-func (p *statsPlugin) SetOption(name, value string) {
+func (p *statsPlugin) Starting() {
+	p.timesLock.Lock()
+	defer p.timesLock.Unlock()
 
+	p.runStart = time.Now()
 }
+func (p *statsPlugin) Finished() {
+	p.timesLock.Lock()
+	defer p.timesLock.Unlock()
 
-func (p *statsPlugin) Starting() {
-
+	p.runTime = time.Since(p.runStart)
 }
+
 func (p *statsPlugin) SuiteStarting(suite string) {
 	// Get the suite so stats are aware of it and it shows up
 	// in the final results
 	p.getSuite(suite)
-}
-func (p *statsPlugin) TestStarting(testName *TestName) {
-
 }
 func (p *statsPlugin) TestPassed(testName *TestName, stats *TestPassedStats) {
+	if testName.IsSubtest() {
+		return
+	}
+
 	s := p.getSuite(testName.SuiteName)
 	atomic.AddInt64(&s.Passed, 1)
+	p.addTestTime(testName, stats.Time)
 }
 func (p *statsPlugin) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	if testName.IsSubtest() {
+		return
+	}
+
 	s := p.getSuite(testName.SuiteName)
 	atomic.AddInt64(&s.Skipped, 1)
 }
 func (p *statsPlugin) TestFailed(testName *TestName, stats *TestFailedStats) {
+	if testName.IsSubtest() {
+		return
+	}
+
 	s := p.getSuite(testName.SuiteName)
 	atomic.AddInt64(&s.Failed, 1)
+	p.addTestTime(testName, stats.Time)
+}
+func (p *statsPlugin) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	if testName.IsSubtest() {
+		return
+	}
+
+	s := p.getSuite(testName.SuiteName)
+	atomic.AddInt64(&s.Quarantined, 1)
+	p.addTestTime(testName, stats.Time)
 }
 func (p *statsPlugin) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	s := p.getSuite(suite)
 
+	p.suitesLock.Lock()
+	defer p.suitesLock.Unlock()
+	s.Time = stats.Time
 }
-func (p *statsPlugin) Finished() {
+
+// Suites returns a copy of the stats for each suite sorted by the suite name.
+func (p *statsPlugin) Suites() []*suiteStats {
+	p.suitesLock.Lock()
+	defer p.suitesLock.Unlock()
+
 	sortedNames := make([]string, 0)
 	for key := range p.suites {
 		sortedNames = append(sortedNames, key)
 	}
 	sort.Strings(sortedNames)
 
-	out := os.Stdout
-	isTerm := terminal.IsTerminal(int(out.Fd()))
-
-	passColor := ansi.ColorFunc("green")
-	failColor := ansi.ColorFunc("red")
-	skipColor := ansi.ColorFunc("yellow")
-
-	if len(sortedNames) > 0 {
-		fmt.Fprintln(out, "")
-		fmt.Fprintf(out, "Suite Results:\n")
-		fmt.Fprintf(out, "--------------\n")
-		for _, name := range sortedNames {
-			suite := p.suites[name]
-
-			totalStr := fmt.Sprintf("%d", suite.Passed+suite.Failed+suite.Skipped)
-
-			passedStr := fmt.Sprintf("%d", suite.Passed)
-			if isTerm && suite.Passed > 0 {
-				passedStr = passColor(passedStr)
-			}
-
-			failedStr := fmt.Sprintf("%d", suite.Failed)
-			if isTerm && suite.Failed > 0 {
-				failedStr = failColor(failedStr)
-			}
-
-			skippedStr := fmt.Sprintf("%d", suite.Skipped)
-			if isTerm && suite.Skipped > 0 {
-				skippedStr = skipColor(skippedStr)
-			}
-
-			fmt.Fprintf(out, "%s - Total: %s, Passed: %s, Failed: %s, Skipped: %s\n",
-				name,
-				totalStr,
-				passedStr,
-				failedStr,
-				skippedStr,
-			)
-		}
-		fmt.Fprintln(out, "")
+	suites := make([]*suiteStats, 0, len(sortedNames))
+	for _, name := range sortedNames {
+		suite := p.suites[name]
+		suites = append(suites, &suiteStats{
+			Name:        suite.Name,
+			Passed:      atomic.LoadInt64(&suite.Passed),
+			Failed:      atomic.LoadInt64(&suite.Failed),
+			Skipped:     atomic.LoadInt64(&suite.Skipped),
+			Quarantined: atomic.LoadInt64(&suite.Quarantined),
+			Time:        suite.Time,
+		})
+	}
+
+	return suites
+}
+
+// RunTime returns how long the whole run took, once it has finished.
+func (p *statsPlugin) RunTime() time.Duration {
+	p.timesLock.Lock()
+	defer p.timesLock.Unlock()
+
+	return p.runTime
+}
+
+// Slowest returns up to count of the tests that took the longest to run,
+// slowest first. Skipped tests and subtests aren't included.
+func (p *statsPlugin) Slowest(count int) []*testTime {
+	p.timesLock.Lock()
+	defer p.timesLock.Unlock()
+
+	sorted := make([]*testTime, len(p.testTimes))
+	copy(sorted, p.testTimes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time > sorted[j].Time
+	})
+
+	if count < len(sorted) {
+		sorted = sorted[:count]
 	}
+
+	return sorted
+}
+
+func (p *statsPlugin) addTestTime(testName *TestName, d time.Duration) {
+	p.timesLock.Lock()
+	defer p.timesLock.Unlock()
+
+	p.testTimes = append(p.testTimes, &testTime{
+		Name: testName,
+		Time: d,
+	})
 }
 
 func (p *statsPlugin) getSuite(name string) *suiteStats {
@@ -0,0 +1,58 @@
+package scenario
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&scenarioPlugin{})
+
+		s.AddSuite(&ScenarioSuite{})
+	})
+}
+
+type scenarioPlugin struct {
+	sweet.BasePlugin
+}
+
+func (p *scenarioPlugin) Name() string { return "Scenario Plugin" }
+func (p *scenarioPlugin) TestPassed(testName *sweet.TestName, stats *sweet.TestPassedStats) {
+	fmt.Printf("{Passed:%s}\n", testName)
+}
+func (p *scenarioPlugin) TestFailed(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	fmt.Printf("{Failed:%s}\n", testName)
+}
+func (p *scenarioPlugin) TestSkipped(testName *sweet.TestName, stats *sweet.TestSkippedStats) {
+	fmt.Printf("{Skipped:%s:%s}\n", testName, stats.Message)
+}
+
+type ScenarioSuite struct{}
+
+func (s *ScenarioSuite) Dependencies() map[string][]string {
+	return map[string][]string{
+		"TestAddToCart": {"TestLogin"},
+		"TestCheckout":  {"TestAddToCart"},
+		"TestReceipt":   {"TestCheckout"},
+	}
+}
+
+func (s *ScenarioSuite) TestLogin(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+func (s *ScenarioSuite) TestAddToCart(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+	t.Fail()
+}
+
+func (s *ScenarioSuite) TestCheckout(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+func (s *ScenarioSuite) TestReceipt(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
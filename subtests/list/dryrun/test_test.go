@@ -0,0 +1,35 @@
+package dryrun
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.AddSuite(&FirstSuite{})
+		s.AddSuite(&SecondSuite{})
+	})
+}
+
+type FirstSuite struct{}
+
+func (s *FirstSuite) SetUpSuite() {
+	fmt.Printf("{SetUpSuite}\n")
+}
+
+func (s *FirstSuite) TestOne(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+func (s *FirstSuite) TestTwo(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+type SecondSuite struct{}
+
+func (s *SecondSuite) TestThree(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
@@ -0,0 +1,61 @@
+package recovery
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&panicPlugin{})
+
+		s.AddSuite(&PanicSuite{})
+	})
+}
+
+type panicPlugin struct{}
+
+func (p *panicPlugin) Name() string                          { return "Panic Plugin" }
+func (p *panicPlugin) Options() *sweet.PluginOptions         { return nil }
+func (p *panicPlugin) SetOption(name, value string)          {}
+func (p *panicPlugin) Starting()                             {}
+func (p *panicPlugin) SuiteStarting(suite string)            {}
+func (p *panicPlugin) TestStarting(testName *sweet.TestName) {}
+func (p *panicPlugin) TestPassed(testName *sweet.TestName, stats *sweet.TestPassedStats) {
+	fmt.Printf("{Passed:%s}\n", testName)
+}
+func (p *panicPlugin) TestFailed(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	if stats.Panicked {
+		fmt.Printf("{Panicked:%s}\n", stats.Name)
+	}
+}
+func (p *panicPlugin) TestSkipped(testName *sweet.TestName, stats *sweet.TestSkippedStats) {}
+func (p *panicPlugin) SuiteFinished(suite string, stats *sweet.SuiteFinishedStats)         {}
+func (p *panicPlugin) Finished()                                                           {}
+
+type PanicSuite struct{}
+
+func (s *PanicSuite) TearDownTest(t sweet.T) {
+	fmt.Printf("{TearDownTest:%s}\n", t.Name())
+}
+
+func (s *PanicSuite) TestIndexOutOfRange(t sweet.T) {
+	values := []int{}
+	idx := 3
+	fmt.Println(values[idx])
+}
+
+func (s *PanicSuite) TestNilDereference(t sweet.T) {
+	var value *struct{ Name string }
+	fmt.Println(value.Name)
+}
+
+func (s *PanicSuite) TestPasses(t sweet.T) {}
+
+func (s *PanicSuite) TestSubtestPanic(t sweet.T) {
+	t.Run("Sub", func(t sweet.T) {
+		panic("subtest panic")
+	})
+}
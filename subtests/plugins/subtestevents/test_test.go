@@ -0,0 +1,74 @@
+package subtestevents
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&eventPlugin{})
+
+		s.AddSuite(&EventSuite{})
+	})
+}
+
+type eventPlugin struct{}
+
+func parentName(testName *sweet.TestName) string {
+	if testName.Parent == nil {
+		return "<nil>"
+	}
+	return testName.Parent.String()
+}
+
+func (p *eventPlugin) Name() string                  { return "Event Plugin" }
+func (p *eventPlugin) Options() *sweet.PluginOptions { return nil }
+func (p *eventPlugin) SetOption(name, value string)  {}
+func (p *eventPlugin) Starting() {
+	fmt.Printf("{RunStarting}\n")
+}
+func (p *eventPlugin) SuiteStarting(suite string) {
+	fmt.Printf("{SuiteStarting:%s}\n", suite)
+}
+func (p *eventPlugin) TestStarting(testName *sweet.TestName) {
+	fmt.Printf("{Starting:%s:%s}\n", testName, parentName(testName))
+}
+func (p *eventPlugin) TestPassed(testName *sweet.TestName, stats *sweet.TestPassedStats) {
+	fmt.Printf("{Passed:%s:%s}\n", testName, parentName(testName))
+}
+func (p *eventPlugin) TestFailed(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	fmt.Printf("{Failed:%s:%s:%d}\n", testName, parentName(testName), len(stats.FailedSubtests))
+}
+func (p *eventPlugin) TestSkipped(testName *sweet.TestName, stats *sweet.TestSkippedStats) {
+	fmt.Printf("{Skipped:%s:%s}\n", testName, parentName(testName))
+}
+func (p *eventPlugin) SuiteFinished(suite string, stats *sweet.SuiteFinishedStats) {}
+func (p *eventPlugin) Finished()                                                   {}
+
+type EventSuite struct{}
+
+func (s *EventSuite) TestSubtests(t sweet.T) {
+	t.Run("Passes", func(t sweet.T) {
+		t.Run("Nested", func(t sweet.T) {})
+	})
+	t.Run("Fails", func(t sweet.T) {
+		t.Fail()
+	})
+	t.Run("Skips", func(t sweet.T) {
+		t.SkipNow()
+	})
+	fmt.Printf("{AfterSubtests}\n")
+}
+
+func (s *EventSuite) TestParallelSubtests(t sweet.T) {
+	t.Run("Passes", func(t sweet.T) {
+		t.Parallel()
+	})
+	t.Run("Fails", func(t sweet.T) {
+		t.Parallel()
+		t.Fail()
+	})
+}
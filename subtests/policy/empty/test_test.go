@@ -0,0 +1,17 @@
+package empty
+
+import (
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.AddSuite(&EmptySuite{})
+	})
+}
+
+type EmptySuite struct{}
+
+func (s *EmptySuite) TestPasses(t sweet.T) {}
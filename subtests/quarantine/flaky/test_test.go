@@ -0,0 +1,49 @@
+package flaky
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+func TestMain(m *testing.M) {
+	RegisterFailHandler(sweet.GomegaFail)
+
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&quarantinePlugin{})
+		s.RegisterPlugin(&fallbackPlugin{})
+
+		s.AddSuite(&FlakySuite{})
+	})
+}
+
+type quarantinePlugin struct {
+	sweet.BasePlugin
+}
+
+func (p *quarantinePlugin) Name() string { return "Quarantine Plugin" }
+func (p *quarantinePlugin) TestQuarantined(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	fmt.Printf("{Quarantined:%s}\n", testName)
+}
+
+type fallbackPlugin struct {
+	sweet.BasePlugin
+}
+
+func (p *fallbackPlugin) Name() string { return "Fallback Plugin" }
+func (p *fallbackPlugin) TestFailed(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	fmt.Printf("{Failed:%s:%t}\n", testName, stats.Quarantined)
+}
+
+type FlakySuite struct{}
+
+func (s *FlakySuite) TestPasses(t sweet.T) {}
+
+func (s *FlakySuite) TestFlaky(t sweet.T) {
+	t.Run("Sub", func(t sweet.T) {
+		Expect(true).To(BeFalse())
+	})
+	Expect(true).To(BeFalse())
+}
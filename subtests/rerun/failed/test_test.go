@@ -0,0 +1,64 @@
+package failed
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	// Start from a results file saying which tests failed last time
+	dir, err := ioutil.TempDir("", "sweet-rerun")
+	if err != nil {
+		panic(err)
+	}
+	pkg := "github.com/aphistic/sweet/subtests/rerun/failed"
+	file := regexp.MustCompile(`[^A-Za-z0-9._-]+`).ReplaceAllString(pkg, "_") + ".json"
+	err = ioutil.WriteFile(filepath.Join(dir, file), []byte(`{
+		"Package": "`+pkg+`",
+		"Failed": ["FailedSuite/TestFailed", "FailedSuite/TestSubtests", "FailedSuite/TestSubtests/Failed"]
+	}`), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	flag.Parse()
+	flag.Set("sweet.results", dir)
+	flag.Set("sweet.rerunfailed", "true")
+
+	sweet.Run(m, func(s *sweet.S) {
+		s.AddSuite(&FailedSuite{})
+		s.AddSuite(&PassedSuite{})
+	})
+}
+
+type FailedSuite struct{}
+
+func (s *FailedSuite) TestPassed(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+func (s *FailedSuite) TestFailed(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+func (s *FailedSuite) TestSubtests(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+	t.Run("Failed", func(t sweet.T) {
+		fmt.Printf("{Ran:%s}\n", t.Name())
+	})
+	t.Run("Passed", func(t sweet.T) {
+		fmt.Printf("{Ran:%s}\n", t.Name())
+	})
+}
+
+type PassedSuite struct{}
+
+func (s *PassedSuite) TestPassed(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
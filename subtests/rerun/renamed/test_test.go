@@ -0,0 +1,46 @@
+package renamed
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+// The test is run several times with RENAMED_RUN saying whether the failing
+// test has been renamed yet and RENAMED_RESULTS where to keep the results.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	flag.Set("sweet.results", os.Getenv("RENAMED_RESULTS"))
+
+	run := os.Getenv("RENAMED_RUN")
+	switch run {
+	case "rerun":
+		flag.Set("sweet.rerunfailed", "true")
+	case "include":
+		flag.Set("sweet.include", "NewSuite")
+	}
+
+	sweet.Run(m, func(s *sweet.S) {
+		if run == "before" {
+			s.AddSuite(&OldSuite{})
+		} else {
+			s.AddSuite(&NewSuite{})
+		}
+	})
+}
+
+type OldSuite struct{}
+
+func (s *OldSuite) TestFails(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+	t.Fail()
+}
+
+type NewSuite struct{}
+
+func (s *NewSuite) TestPasses(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
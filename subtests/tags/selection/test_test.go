@@ -0,0 +1,56 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&tagsPlugin{})
+
+		s.AddSuite(&IntegrationSuite{}, sweet.WithTags("integration"))
+		s.AddSuite(&UnitSuite{})
+	})
+}
+
+type tagsPlugin struct {
+	sweet.BasePlugin
+}
+
+func (p *tagsPlugin) Name() string { return "Tags Plugin" }
+func (p *tagsPlugin) TestPassed(testName *sweet.TestName, stats *sweet.TestPassedStats) {
+	fmt.Printf("{Passed:%s:%s}\n", testName, strings.Join(testName.Tags, ","))
+}
+func (p *tagsPlugin) SuiteFinished(suite string, stats *sweet.SuiteFinishedStats) {
+	fmt.Printf("{SuiteFinished:%s:%s}\n", suite, strings.Join(stats.Tags, ","))
+}
+
+type IntegrationSuite struct{}
+
+func (s *IntegrationSuite) Tags() map[string][]string {
+	return map[string][]string{
+		"TestSlow": {"slow"},
+	}
+}
+
+func (s *IntegrationSuite) TestFast(t sweet.T) {
+	t.Run("Sub", func(t sweet.T) {})
+}
+
+func (s *IntegrationSuite) TestSlow(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
+
+type UnitSuite struct{}
+
+func (s *UnitSuite) SetUpSuite() {
+	fmt.Printf("{SetUpSuite:UnitSuite}\n")
+}
+
+func (s *UnitSuite) TestUnit(t sweet.T) {
+	fmt.Printf("{Ran:%s}\n", t.Name())
+}
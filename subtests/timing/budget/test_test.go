@@ -0,0 +1,43 @@
+package budget
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aphistic/sweet"
+)
+
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(&budgetPlugin{})
+
+		s.AddSuite(&BudgetSuite{})
+	})
+}
+
+type budgetPlugin struct {
+	sweet.BasePlugin
+}
+
+func (p *budgetPlugin) Name() string { return "Budget Plugin" }
+func (p *budgetPlugin) TestPassed(testName *sweet.TestName, stats *sweet.TestPassedStats) {
+	fmt.Printf("{Passed:%s}\n", testName)
+}
+func (p *budgetPlugin) TestFailed(testName *sweet.TestName, stats *sweet.TestFailedStats) {
+	fmt.Printf("{Failed:%s:%s}\n", testName, stats.Message)
+}
+
+type BudgetSuite struct{}
+
+func (s *BudgetSuite) TestFast(t sweet.T) {}
+
+func (s *BudgetSuite) TestSlow(t sweet.T) {
+	time.Sleep(200 * time.Millisecond)
+}
+
+func (s *BudgetSuite) TestSlowSubtests(t sweet.T) {
+	t.Run("Sub", func(t sweet.T) {
+		time.Sleep(200 * time.Millisecond)
+	})
+}
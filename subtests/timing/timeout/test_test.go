@@ -0,0 +1,21 @@
+package timeout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aphistic/sweet"
+)
+
+// The timeout in sweet.yaml stops the test long before it finishes.
+func TestMain(m *testing.M) {
+	sweet.Run(m, func(s *sweet.S) {
+		s.AddSuite(&TimeoutSuite{})
+	})
+}
+
+type TimeoutSuite struct{}
+
+func (s *TimeoutSuite) TestSlow(t sweet.T) {
+	time.Sleep(30 * time.Second)
+}
@@ -2,19 +2,29 @@ package sweet
 
 import (
 	"fmt"
-	"path"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
+// SuiteOption changes how a suite added with AddSuite is run.
+type SuiteOption func(runner *suiteRunner)
+
 type suiteRunner struct {
-	s      *S
-	suite  interface{}
-	differ *differ
+	s     *S
+	suite interface{}
 
 	suiteFailed bool
+	sourceOrder bool
+	tags        []string
+
+	// unsuccessful holds whether each test that failed or was skipped
+	// "failed" or was "skipped", so the tests depending on them can be
+	// skipped.
+	unsuccessfulLock sync.Mutex
+	unsuccessful     map[string]string
 
 	suppressDeprecation bool
 	deprecatedUsages    []*TestName
@@ -24,7 +34,7 @@ func newSuiteRunner(s *S, suite interface{}) *suiteRunner {
 	return &suiteRunner{
 		s:                s,
 		suite:            suite,
-		differ:           newDiffer(),
+		unsuccessful:     make(map[string]string),
 		deprecatedUsages: []*TestName{},
 	}
 }
@@ -33,15 +43,8 @@ func (s *suiteRunner) addDeprecationWarning(testName *TestName) {
 	s.deprecatedUsages = append(s.deprecatedUsages, testName)
 }
 
-func (s *suiteRunner) runPlugins(f func(plugin Plugin)) {
-	for _, plugin := range s.s.plugins {
-		f(plugin)
-	}
-}
-
-func (s *suiteRunner) Run(t *testing.T) {
-	suiteStart := time.Now()
-
+// name returns the name of the suite's type.
+func (s *suiteRunner) name() string {
 	suiteVal := reflect.ValueOf(s.suite)
 	suiteType := suiteVal.Type()
 
@@ -55,30 +58,57 @@ func (s *suiteRunner) Run(t *testing.T) {
 		}
 	}
 
+	return suiteName
+}
+
+// included returns true if the suite is selected by -sweet.include,
+// -sweet.exclude and -sweet.rerunfailed.
+func (s *suiteRunner) included(suiteName string) bool {
+	lowerName := strings.ToLower(suiteName)
+	if len(flagInclude) > 0 {
+		found := false
+		for _, name := range flagInclude {
+			if strings.ToLower(name) == lowerName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(flagExclude) > 0 {
+		for _, name := range flagExclude {
+			if strings.ToLower(name) == lowerName {
+				return false
+			}
+		}
+	}
+
+	return s.s.shouldRun(newTestName(suiteName, nil))
+}
+
+func (s *suiteRunner) Run(t *testing.T) {
+	suiteStart := time.Now()
+
+	suiteVal := reflect.ValueOf(s.suite)
+	suiteType := suiteVal.Type()
+
+	suiteName := s.name()
+
 	t.Run(suiteName, func(t *testing.T) {
 		if *flagParallelSuites {
 			t.Parallel()
 		}
 
-		lowerName := strings.ToLower(suiteName)
-		if len(flagInclude) > 0 {
-			found := false
-			for _, name := range flagInclude {
-				if strings.ToLower(name) == lowerName {
-					found = true
-					break
-				}
-			}
-			if !found {
-				return
-			}
+		if !s.included(suiteName) {
+			return
 		}
-		if len(flagExclude) > 0 {
-			for _, name := range flagExclude {
-				if strings.ToLower(name) == lowerName {
-					return
-				}
-			}
+
+		// Don't set up a suite when none of its tests have the tags
+		tests := s.selectTests(suiteName, suiteType)
+		if len(flagTags) > 0 && len(tests) == 0 {
+			return
 		}
 
 		setUpSuiteVal := suiteVal.MethodByName(defSetUpSuite.Name)
@@ -105,25 +135,27 @@ func (s *suiteRunner) Run(t *testing.T) {
 			}
 		}
 
-		s.runPlugins(func(plugin Plugin) {
-			plugin.SuiteStarting(suiteName)
+		s.s.emitSuiteStarting(suiteName, &SuiteStartingStats{
+			Tags: s.tags,
 		})
-		for idx := 0; idx < suiteVal.NumMethod(); idx++ {
-			methodType := suiteType.Method(idx)
-
-			if methodType.Name[:4] == "Test" {
-				methodVal := suiteVal.Method(idx)
-				testName := methodType.Name
-				t.Run(testName, func(t *testing.T) {
-					s.testRunner(
-						testName,
-						t,
-						methodVal,
-						suiteName,
-						suiteVal,
-					)
-				})
-			}
+		for _, idx := range tests {
+			methodVal := suiteVal.Method(idx)
+			testName := suiteType.Method(idx).Name
+			t.Run(testName, func(t *testing.T) {
+				if dep, result, ok := s.unsuccessfulDependency(testName); ok {
+					s.skipForDependency(t, s.taggedTestName(suiteName, testName),
+						fmt.Sprintf("dependency %s: %s", result, formatName(suiteName, dep)))
+					return
+				}
+
+				s.testRunner(
+					testName,
+					t,
+					methodVal,
+					suiteName,
+					suiteVal,
+				)
+			})
 		}
 
 		v, err = defTearDownSuite.Validate(tearDownSuiteVal)
@@ -147,10 +179,9 @@ func (s *suiteRunner) Run(t *testing.T) {
 			}
 		}
 
-		s.runPlugins(func(plugin Plugin) {
-			plugin.SuiteFinished(suiteName, &SuiteFinishedStats{
-				Time: time.Since(suiteStart),
-			})
+		s.s.emitSuiteFinished(suiteName, &SuiteFinishedStats{
+			Time: time.Since(suiteStart),
+			Tags: s.tags,
 		})
 	})
 }
@@ -162,12 +193,14 @@ func (s *suiteRunner) testRunner(
 	suiteName string,
 	suiteVal reflect.Value,
 ) {
-	fullTestName := newTestName(suiteName, []string{testName})
+	fullTestName := s.taggedTestName(suiteName, testName)
 
 	setUpTestVal := suiteVal.MethodByName(defSetUpTest.Name)
 	tearDownTestVal := suiteVal.MethodByName(defTearDownTest.Name)
 
 	wrapT := newSweetT(t, fullTestName)
+	wrapT.runner = s
+	wrapT.quarantined = s.s.isQuarantined(fullTestName)
 
 	tVal := reflect.ValueOf(t)
 	wrapTVal := reflect.ValueOf(wrapT)
@@ -205,42 +238,8 @@ func (s *suiteRunner) testRunner(
 		Frames: make([]*TestFailedFrame, 0),
 	}
 	testStart := time.Now()
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				switch result := r.(type) {
-				case *testFailed:
-					if result.TestName != nil {
-						failureStats.Name = result.TestName
-					}
-
-					failureStats.Message = result.Message
-					failureStats.Frames = make(
-						[]*TestFailedFrame,
-						len(result.Frames),
-					)
-					frameCount := len(result.Frames) - 1
-					for idx := frameCount; idx >= 0; idx-- {
-						frame := result.Frames[idx]
-						failureStats.Frames[frameCount-idx] = &TestFailedFrame{
-							File:   frame.Filename,
-							Line:   frame.LineNumber,
-							Hidden: frame.HiddenFrame,
-						}
-					}
-					wrapT.Fail()
-				case *testSkipped:
-					// Nothing to do for this because it was handled before
-					// the panic
-				default:
-					panic(r)
-				}
-			}
-		}()
-
-		s.runPlugins(func(plugin Plugin) {
-			plugin.TestStarting(fullTestName)
-		})
+	s.recoverTest(wrapT, failureStats, func() {
+		s.s.emitTestStarting(fullTestName)
 
 		v, err := defTest.Validate(methodVal)
 		if err == errDeprecated {
@@ -266,7 +265,7 @@ func (s *suiteRunner) testRunner(
 				methodVal.Call([]reflect.Value{wrapTVal})
 			}
 		}
-	}()
+	})
 
 	v, err = defTearDownTest.Validate(tearDownTestVal)
 	if err == errDeprecated {
@@ -295,41 +294,120 @@ func (s *suiteRunner) testRunner(
 		tearDownAllTests(wrapT)
 	}
 
-	s.runPlugins(func(plugin Plugin) {
-		if wrapT.Failed() {
-			plugin.TestFailed(fullTestName, failureStats)
-		} else if wrapT.Skipped() {
-			plugin.TestSkipped(fullTestName, &TestSkippedStats{
-				Time: time.Since(testStart),
-			})
-		} else {
-			plugin.TestPassed(fullTestName, &TestPassedStats{
-				Time: time.Since(testStart),
-			})
-		}
+	wrapT.finish(func() {
+		s.reportResult(wrapT, failureStats, testStart)
 	})
+}
 
-	if wrapT.Failed() {
-		s.suiteFailed = true
+// runSubtest runs a subtest started with T.Run and reports it to the plugins
+// the same way a test method would be.
+func (s *suiteRunner) runSubtest(t *sweetT, f func(t T)) {
+	failureStats := &TestFailedStats{
+		Name:   t.name,
+		Frames: make([]*TestFailedFrame, 0),
+	}
 
-		fmt.Printf("-------------------------------------------------\n")
-		fmt.Printf("FAIL: %s\n\n", failureStats.Name)
+	testStart := time.Now()
+	s.recoverTest(t, failureStats, func() {
+		s.s.emitTestStarting(t.name)
 
-		for _, line := range wrapT.output {
-			fmt.Print(line)
-		}
-		if len(wrapT.output) > 0 {
-			fmt.Printf("\n\n")
-		}
+		f(t)
+	})
 
-		for _, frame := range failureStats.Frames {
-			if !frame.Hidden {
-				fmt.Printf("%s:%d\n", path.Base(frame.File), frame.Line)
+	t.finish(func() {
+		s.reportResult(t, failureStats, testStart)
+	})
+}
+
+// recoverTest calls f and recovers from any failures, skips or other panics
+// that happen while running it, adding details to stats when the test failed.
+func (s *suiteRunner) recoverTest(t *sweetT, stats *TestFailedStats, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch result := r.(type) {
+			case *testFailed:
+				s.applyFailure(stats, result)
+				t.Fail()
+			case *testSkipped:
+				// Nothing to do for this because it was handled before
+				// the panic
+			default:
+				s.applyFailure(stats, panicFailure(r))
+				t.Fail()
 			}
 		}
+	}()
+
+	f()
+}
 
-		diffMessage := s.differ.ProcessMessage(failureStats.Message)
+func (s *suiteRunner) reportResult(t *sweetT, failureStats *TestFailedStats, testStart time.Time) {
+	testTime := time.Since(testStart)
+
+	// A test going over the budget fails, but a subtest only counts towards
+	// its parent's time.
+	if *flagBudget > 0 && testTime > *flagBudget &&
+		!t.name.IsSubtest() && !t.Failed() && !t.Skipped() {
+		failureStats.Message = fmt.Sprintf(
+			"Test took %s, which is longer than the budget of %s",
+			testTime.Round(time.Millisecond), *flagBudget,
+		)
+		t.Fail()
+	}
+
+	failureStats.Time = testTime
+	failureStats.Output = t.logOutput()
+	failureStats.FailedSubtests = t.failedSubtests()
+
+	if t.Failed() {
+		failureStats.Quarantined = t.quarantined
+		s.s.emitTestFailed(t.name, failureStats)
+	} else if t.Skipped() {
+		s.s.emitTestSkipped(t.name, &TestSkippedStats{
+			Time:    testTime,
+			Message: t.skipReason(),
+		})
+	} else {
+		s.s.emitTestPassed(t.name, &TestPassedStats{
+			Time: testTime,
+		})
+	}
 
-		fmt.Printf("%s\n\n", diffMessage)
+	if !t.name.IsSubtest() && (t.Failed() || t.Skipped()) {
+		result := "failed"
+		if !t.Failed() {
+			result = "skipped"
+		}
+		s.unsuccessfulLock.Lock()
+		s.unsuccessful[t.name.TestNames[0]] = result
+		s.unsuccessfulLock.Unlock()
+	}
+
+	if t.Failed() && !t.quarantined {
+		s.suiteFailed = true
+	}
+}
+
+func (s *suiteRunner) applyFailure(stats *TestFailedStats, result *testFailed) {
+	stats.Message = result.Message
+	stats.Panicked = result.Panicked
+	stats.PanicValue = result.PanicValue
+	stats.Stack = result.Stack
+
+	stats.Frames = make([]*TestFailedFrame, len(result.Frames))
+	frameCount := len(result.Frames) - 1
+	for idx := frameCount; idx >= 0; idx-- {
+		frame := result.Frames[idx]
+		stats.Frames[frameCount-idx] = &TestFailedFrame{
+			File:     frame.Filename,
+			Line:     frame.LineNumber,
+			Function: frame.Function,
+			Hidden:   frame.HiddenFrame,
+		}
+		if !frame.HiddenFrame && *flagSnippet > 0 {
+			stats.Frames[frameCount-idx].Source = loadSnippet(
+				frame.Filename, frame.LineNumber, *flagSnippet,
+			)
+		}
 	}
 }
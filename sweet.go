@@ -7,6 +7,14 @@ import (
 type TestName struct {
 	SuiteName string
 	TestNames []string
+
+	// Parent is the name of the test that started this one using T.Run, it's
+	// nil for test methods on a suite.
+	Parent *TestName
+
+	// Tags are the tags of the test, subtests have the same tags as their
+	// parent.
+	Tags []string
 }
 
 func newTestName(suite string, test []string) *TestName {
@@ -16,6 +24,19 @@ func newTestName(suite string, test []string) *TestName {
 	}
 }
 
+func newSubtestName(parent *TestName, test string) *TestName {
+	name := parent.Clone()
+	name.AddTestName(test)
+	name.Parent = parent
+
+	return name
+}
+
+// IsSubtest returns true if the test was started by another test using T.Run.
+func (tn *TestName) IsSubtest() bool {
+	return tn.Parent != nil
+}
+
 func (tn *TestName) String() string {
 	testName := make([]string, 0, len(tn.TestNames)+1)
 	testName = append(testName, tn.SuiteName)
@@ -25,8 +46,10 @@ func (tn *TestName) String() string {
 
 func (tn *TestName) Clone() *TestName {
 	newName := &TestName{
-		SuiteName:tn.SuiteName,
+		SuiteName: tn.SuiteName,
 		TestNames: []string{},
+		Parent:    tn.Parent,
+		Tags:      tn.Tags,
 	}
 	for _, testName := range tn.TestNames {
 		newName.TestNames = append(newName.TestNames, testName)
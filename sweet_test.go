@@ -16,12 +16,34 @@ func TestMain(m *testing.M) {
 	RegisterFailHandler(GomegaFail)
 
 	Run(m, func(s *S) {
+		s.AddSuite(&AnnotationsSuite{})
+		s.AddSuite(&ConfigSuite{})
+		s.AddSuite(&ConsoleSuite{})
 		s.AddSuite(&DefsSuite{})
+		s.AddSuite(&DepsSuite{})
 		s.AddSuite(&differSuite{})
+		s.AddSuite(&EventsSuite{})
 		s.AddSuite(&FailureSuite{})
+		s.AddSuite(&HistorySuite{})
+		s.AddSuite(&HTMLSuite{})
+		s.AddSuite(&JSONSuite{})
+		s.AddSuite(&JUnitSuite{})
+		s.AddSuite(&ListSuite{})
+		s.AddSuite(&OptionsSuite{})
+		s.AddSuite(&OrderSuite{})
+		s.AddSuite(&PkgPathSuite{})
+		s.AddSuite(&PluginSuite{})
+		s.AddSuite(&PolicySuite{})
+		s.AddSuite(&QuarantineSuite{})
+		s.AddSuite(&RerunSuite{})
 		s.AddSuite(&RunnerSuite{})
 		s.AddSuite(&ReturnCodeSuite{})
+		s.AddSuite(&SnippetSuite{})
+		s.AddSuite(&TagsSuite{})
+		s.AddSuite(&TAPSuite{})
+		s.AddSuite(&TeamCitySuite{})
 		s.AddSuite(&TSuite{})
+		s.AddSuite(&TestNameSuite{})
 
 		v1DefSuite := &SweetDefsV1Suite{}
 		s.AddSuite(v1DefSuite)
@@ -34,6 +56,12 @@ func TestMain(m *testing.M) {
 }
 
 func runSubTests(name ...string) (int, string, string, error) {
+	return runSubTestsEnv(nil, name...)
+}
+
+// runSubTestsEnv runs the sub tests with the variables in env added to the
+// environment.
+func runSubTestsEnv(env []string, name ...string) (int, string, string, error) {
 	names := []string{"subtests"}
 	names = append(names, name...)
 	fullPath := path.Join(names...)
@@ -48,6 +76,7 @@ func runSubTests(name ...string) (int, string, string, error) {
 
 	cmd := exec.Command("go", "test")
 	cmd.Dir = fullPath
+	cmd.Env = append(os.Environ(), env...)
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
@@ -88,3 +117,21 @@ func runSubTests(name ...string) (int, string, string, error) {
 
 	return exitCode, string(stdout), string(stderr), nil
 }
+
+type TestNameSuite struct{}
+
+func (s *TestNameSuite) TestSubtestName(t T) {
+	parent := newTestName("MySuite", []string{"TestThing"})
+	sub := newSubtestName(parent, "Sub")
+
+	Expect(sub.String()).To(Equal("MySuite/TestThing/Sub"))
+	Expect(sub.Parent).To(Equal(parent))
+	Expect(sub.IsSubtest()).To(BeTrue())
+	Expect(parent.IsSubtest()).To(BeFalse())
+
+	// Adding to the subtest shouldn't change the parent
+	Expect(parent.TestNames).To(Equal([]string{"TestThing"}))
+
+	clone := sub.Clone()
+	Expect(clone.Parent).To(Equal(parent))
+}
@@ -75,10 +75,25 @@ type sweetT struct {
 
 	lock sync.RWMutex
 
-	skipped bool
-	failed  bool
-
-	util *sweetUtil
+	skipped     bool
+	skipMessage string
+	failed      bool
+	// quarantined tests record failures without failing the testing.T so
+	// they don't fail the run.
+	quarantined bool
+
+	subtestsFailed []*TestName
+
+	// pending counts the test's own function and the subtests it started
+	// that haven't finished. Parallel subtests only start once the test
+	// function has returned, so the test is reported by whichever of them
+	// finishes last.
+	pending int
+	report  func()
+	parent  *sweetT
+
+	runner *suiteRunner
+	util   *sweetUtil
 }
 
 func newSweetT(t *testing.T, name *TestName) *sweetT {
@@ -86,7 +101,8 @@ func newSweetT(t *testing.T, name *TestName) *sweetT {
 		t:    t,
 		name: name,
 
-		output: make([]string, 0),
+		output:  make([]string, 0),
+		pending: 1,
 	}
 	newT.util = &sweetUtil{
 		t: newT,
@@ -108,7 +124,9 @@ func (t *sweetT) Fail() {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
-	t.t.Fail()
+	if !t.quarantined {
+		t.t.Fail()
+	}
 	t.failed = true
 }
 func (t *sweetT) FailNow() {
@@ -119,7 +137,7 @@ func (t *sweetT) Failed() bool {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
 
-	return t.failed
+	return t.failed || len(t.subtestsFailed) > 0
 }
 
 func (t *sweetT) Fatal(args ...interface{}) {
@@ -132,16 +150,31 @@ func (t *sweetT) Fatalf(format string, args ...interface{}) {
 }
 
 func (t *sweetT) Log(args ...interface{}) {
-	t.logLock.Lock()
-	defer t.logLock.Unlock()
-
-	t.output = append(t.output, fmt.Sprint(args...))
+	t.addOutput(fmt.Sprint(args...))
 }
 func (t *sweetT) Logf(format string, args ...interface{}) {
+	t.addOutput(fmt.Sprintf(format, args...))
+}
+
+func (t *sweetT) addOutput(output string) {
 	t.logLock.Lock()
-	defer t.logLock.Unlock()
+	t.output = append(t.output, output)
+	t.logLock.Unlock()
+
+	if t.runner != nil {
+		t.runner.s.emitTestOutput(t.name, output)
+	}
+}
+
+// logOutput returns a copy of everything logged by the test.
+func (t *sweetT) logOutput() []string {
+	t.logLock.RLock()
+	defer t.logLock.RUnlock()
+
+	output := make([]string, len(t.output))
+	copy(output, t.output)
 
-	t.output = append(t.output, fmt.Sprintf(format, args...))
+	return output
 }
 
 func (t *sweetT) Name() string {
@@ -153,30 +186,88 @@ func (t *sweetT) Parallel() {
 }
 
 func (t *sweetT) Run(name string, f func(t T)) bool {
-	var panicValue interface{}
-
-	subName := t.name.Clone()
-	subName.AddTestName(name)
-
-	runRes := t.t.Run(name, func(t *testing.T) {
-		defer func() {
-			if r := recover(); r != nil {
-				panicValue = r
-			}
-		}()
-		f(newSweetT(t, subName))
-	})
+	subName := newSubtestName(t.name, name)
+	if t.runner != nil && !t.runner.s.shouldRun(subName) {
+		return true
+	}
+
+	return t.t.Run(name, func(subT *testing.T) {
+		wrapT := newSweetT(subT, subName)
+		wrapT.runner = t.runner
+		wrapT.parent = t
+		wrapT.quarantined = t.quarantined
+		if t.runner != nil && t.runner.s.isQuarantined(subName) {
+			wrapT.quarantined = true
+		}
+
+		t.lock.Lock()
+		t.pending++
+		t.lock.Unlock()
 
-	if panicValue != nil {
-		if pvTestFailed, ok := panicValue.(*testFailed); ok {
-			if pvTestFailed.TestName == nil {
-				pvTestFailed.TestName = subName
-			}
+		if t.runner == nil {
+			f(wrapT)
+			wrapT.finish(nil)
+			return
 		}
-		panic(panicValue)
+		t.runner.runSubtest(wrapT, f)
+	})
+}
+
+// finish is called when the test function has returned, with the function
+// reporting its result. The result is reported right away unless there are
+// parallel subtests still to run, then it's reported once they've finished.
+func (t *sweetT) finish(report func()) {
+	t.lock.Lock()
+	t.report = report
+	t.pending--
+	done := t.pending == 0
+	t.lock.Unlock()
+
+	if done {
+		t.complete()
+	}
+}
+
+// complete reports the test's result once it and its subtests have finished
+// and lets its parent know. This happens before the testing package considers
+// the test finished so it can still be failed.
+func (t *sweetT) complete() {
+	if t.report != nil {
+		t.report()
+	}
+	if t.parent != nil {
+		t.parent.subtestFinished(t)
+	}
+}
+
+func (t *sweetT) subtestFinished(sub *sweetT) {
+	failed := sub.t.Failed()
+
+	t.lock.Lock()
+	if failed {
+		t.subtestsFailed = append(t.subtestsFailed, sub.name)
+	}
+	t.pending--
+	done := t.pending == 0
+	t.lock.Unlock()
+
+	if done {
+		t.complete()
+	}
+}
+
+func (t *sweetT) failedSubtests() []*TestName {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if len(t.subtestsFailed) == 0 {
+		return nil
 	}
 
-	return runRes
+	failed := make([]*TestName, len(t.subtestsFailed))
+	copy(failed, t.subtestsFailed)
+
+	return failed
 }
 
 func (t *sweetT) Skip(args ...interface{}) {
@@ -184,7 +275,8 @@ func (t *sweetT) Skip(args ...interface{}) {
 	defer t.lock.Unlock()
 
 	t.skipped = true
-	skipTest(fmt.Sprint(args...))
+	t.skipMessage = fmt.Sprint(args...)
+	skipTest(t.skipMessage)
 }
 func (t *sweetT) SkipNow() {
 	t.lock.Lock()
@@ -198,7 +290,8 @@ func (t *sweetT) Skipf(format string, args ...interface{}) {
 	defer t.lock.Unlock()
 
 	t.skipped = true
-	skipTest(fmt.Sprintf(format, args...))
+	t.skipMessage = fmt.Sprintf(format, args...)
+	skipTest(t.skipMessage)
 }
 func (t *sweetT) Skipped() bool {
 	t.lock.RLock()
@@ -207,6 +300,13 @@ func (t *sweetT) Skipped() bool {
 	return t.skipped
 }
 
+func (t *sweetT) skipReason() string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.skipMessage
+}
+
 func (t *sweetT) Sweet() SweetUtil {
 	return t.util
 }
@@ -0,0 +1,101 @@
+package sweet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaggedSuite is implemented by suites tagging their tests, such as marking
+// the slow ones so they can be left out with -sweet.tags. It returns the tags
+// for each test method by name.
+type TaggedSuite interface {
+	Tags() map[string][]string
+}
+
+// WithTags is an AddSuite option tagging every test in the suite.
+func WithTags(tags ...string) SuiteOption {
+	return func(runner *suiteRunner) {
+		runner.tags = append(runner.tags, tags...)
+	}
+}
+
+// testTags returns the tags of a test, starting with the suite's tags, or nil
+// if it doesn't have any.
+func (s *suiteRunner) testTags(testName string) []string {
+	var testTags []string
+	if tagged, ok := s.suite.(TaggedSuite); ok {
+		testTags = tagged.Tags()[testName]
+	}
+
+	if len(s.tags) == 0 && len(testTags) == 0 {
+		return nil
+	}
+
+	tags := make([]string, 0, len(s.tags)+len(testTags))
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, s.tags...), testTags...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// taggedTestName returns the name of a test method along with its tags.
+func (s *suiteRunner) taggedTestName(suiteName string, testName string) *TestName {
+	name := newTestName(suiteName, []string{testName})
+	name.Tags = s.testTags(testName)
+
+	return name
+}
+
+// checkTagExprs makes sure the tag expressions given with -sweet.tags are
+// valid.
+func checkTagExprs(exprs []string) error {
+	for _, expr := range exprs {
+		for _, term := range strings.Split(expr, ",") {
+			if strings.TrimPrefix(strings.TrimSpace(term), "!") == "" {
+				return fmt.Errorf("tag expression \"%s\" has an empty tag", expr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchTags returns true if the tags match any of the tag expressions, or
+// there aren't any expressions. An expression is a list of tags separated by
+// commas which must all match, where a tag starting with "!" matches when the
+// test doesn't have that tag.
+func matchTags(exprs []string, tags []string) bool {
+	if len(exprs) == 0 {
+		return true
+	}
+
+	hasTag := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		hasTag[tag] = true
+	}
+
+	for _, expr := range exprs {
+		matched := true
+		for _, term := range strings.Split(expr, ",") {
+			term = strings.TrimSpace(term)
+			if strings.HasPrefix(term, "!") {
+				matched = !hasTag[term[1:]]
+			} else {
+				matched = hasTag[term]
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
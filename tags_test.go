@@ -0,0 +1,57 @@
+package sweet
+
+import (
+	. "github.com/onsi/gomega"
+)
+
+type TagsSuite struct{}
+
+type tagsTaggedSuite struct{}
+
+func (s *tagsTaggedSuite) Tags() map[string][]string {
+	return map[string][]string{
+		"TestSlow": {"slow", "integration"},
+	}
+}
+func (s *tagsTaggedSuite) TestFast(t T) {}
+func (s *tagsTaggedSuite) TestSlow(t T) {}
+
+func (s *TagsSuite) TestMatchTags(t T) {
+	Expect(matchTags(nil, nil)).To(BeTrue())
+	Expect(matchTags(nil, []string{"slow"})).To(BeTrue())
+
+	exprs := []string{"integration,!slow"}
+	Expect(matchTags(exprs, []string{"integration"})).To(BeTrue())
+	Expect(matchTags(exprs, []string{"integration", "slow"})).To(BeFalse())
+	Expect(matchTags(exprs, []string{"unit"})).To(BeFalse())
+	Expect(matchTags(exprs, nil)).To(BeFalse())
+
+	Expect(matchTags([]string{"!slow"}, nil)).To(BeTrue())
+
+	// Each expression is an alternative
+	exprs = []string{"integration, !slow", "unit"}
+	Expect(matchTags(exprs, []string{"unit", "slow"})).To(BeTrue())
+	Expect(matchTags(exprs, []string{"integration"})).To(BeTrue())
+	Expect(matchTags(exprs, []string{"integration", "slow"})).To(BeFalse())
+}
+
+func (s *TagsSuite) TestCheckTagExprs(t T) {
+	Expect(checkTagExprs(nil)).To(BeNil())
+	Expect(checkTagExprs([]string{"integration,!slow", "unit"})).To(BeNil())
+	Expect(checkTagExprs([]string{"integration,"})).ToNot(BeNil())
+	Expect(checkTagExprs([]string{"!"})).ToNot(BeNil())
+}
+
+func (s *TagsSuite) TestTestTags(t T) {
+	runner := newSuiteRunner(&S{}, &tagsTaggedSuite{})
+	Expect(runner.testTags("TestFast")).To(BeNil())
+	Expect(runner.testTags("TestSlow")).To(Equal([]string{"slow", "integration"}))
+
+	WithTags("integration", "db")(runner)
+	Expect(runner.testTags("TestFast")).To(Equal([]string{"integration", "db"}))
+	Expect(runner.testTags("TestSlow")).To(Equal([]string{"integration", "db", "slow"}))
+
+	name := runner.taggedTestName("tagsTaggedSuite", "TestSlow")
+	Expect(name.Tags).To(Equal([]string{"integration", "db", "slow"}))
+	Expect(newSubtestName(name, "Sub").Tags).To(Equal(name.Tags))
+}
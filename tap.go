@@ -0,0 +1,320 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	RegisterReporter("tap", func() Plugin { return NewTAPReporter() })
+}
+
+// tapNode is a suite, test or subtest waiting to be written once its suite
+// has finished.
+type tapNode struct {
+	name     string
+	children []*tapNode
+
+	failed      bool
+	quarantined bool
+	skipped     bool
+	skipMessage string
+	diagnostics yaml.MapSlice
+}
+
+// tapReporter writes results using the Test Anything Protocol. Each suite is
+// written as a subtest of the run once it's finished, with its tests and their
+// subtests nested inside it, so suites running in parallel don't mix their
+// output together.
+type tapReporter struct {
+	outputPath string
+	version    string
+
+	out    io.Writer
+	closer io.Closer
+	err    error
+
+	suites     map[string]*tapNode
+	tests      map[string]*tapNode
+	suiteCount int
+}
+
+// NewTAPReporter creates a reporter writing test results as TAP.
+func NewTAPReporter() Plugin {
+	return newTAPReporter()
+}
+
+func newTAPReporter() *tapReporter {
+	return &tapReporter{
+		outputPath: "-",
+		version:    "14",
+
+		suites: make(map[string]*tapNode),
+		tests:  make(map[string]*tapNode),
+	}
+}
+
+func (p *tapReporter) Name() string {
+	return "TAP Reporter"
+}
+
+func (p *tapReporter) Options() *PluginOptions {
+	return &PluginOptions{
+		Prefix: "tap",
+		Options: map[string]*PluginOption{
+			"output": {
+				Help:    "Path of the file to write TAP output to, or - for stdout",
+				Default: "-",
+				Type:    OptionString,
+			},
+			"version": {
+				Help:    "TAP version to declare in the output",
+				Default: "14",
+				Type:    OptionEnum,
+				Values:  []string{"13", "14"},
+			},
+		},
+	}
+}
+
+func (p *tapReporter) SetOption(name, value string) {
+	switch name {
+	case "output":
+		p.outputPath = value
+	case "version":
+		p.version = value
+	}
+}
+
+func (p *tapReporter) Starting() {
+	if p.out == nil {
+		p.out, p.closer, p.err = openOutput(p.outputPath)
+		if p.err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Could not write TAP output to %s: %s\n", p.outputPath, p.err)
+		}
+	}
+
+	p.write("TAP version %s\n", p.version)
+}
+
+func (p *tapReporter) Finished() {
+	p.write("1..%d\n", p.suiteCount)
+
+	if p.closer != nil {
+		err := p.closer.Close()
+		if err != nil && p.err == nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Could not write TAP output to %s: %s\n", p.outputPath, err)
+		}
+		p.closer = nil
+	}
+}
+
+func (p *tapReporter) SuiteStarting(suite string) {
+	p.suites[suite] = &tapNode{name: suite}
+}
+
+func (p *tapReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	node, ok := p.suites[suite]
+	if !ok {
+		return
+	}
+	delete(p.suites, suite)
+
+	node.diagnostics = yaml.MapSlice{
+		{Key: "duration_ms", Value: durationMillis(stats.Time.Seconds())},
+	}
+	for _, child := range node.children {
+		if child.failed && !child.quarantined {
+			node.failed = true
+		}
+	}
+
+	p.suiteCount++
+
+	var buf strings.Builder
+	writeTAPNode(&buf, node, p.suiteCount, "")
+	p.write("%s", buf.String())
+}
+
+func (p *tapReporter) TestStarting(testName *TestName) {
+	node := &tapNode{name: testName.TestNames[len(testName.TestNames)-1]}
+	p.tests[testName.String()] = node
+
+	if testName.Parent != nil {
+		if parent, ok := p.tests[testName.Parent.String()]; ok {
+			parent.children = append(parent.children, node)
+			return
+		}
+	}
+
+	suite, ok := p.suites[testName.SuiteName]
+	if !ok {
+		suite = &tapNode{name: testName.SuiteName}
+		p.suites[testName.SuiteName] = suite
+	}
+	suite.children = append(suite.children, node)
+}
+
+func (p *tapReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	node := p.finishTest(testName)
+	node.diagnostics = yaml.MapSlice{
+		{Key: "duration_ms", Value: durationMillis(stats.Time.Seconds())},
+	}
+}
+
+func (p *tapReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	node := p.finishTest(testName)
+	node.failed = true
+	node.diagnostics = tapFailureDiagnostics(stats)
+}
+
+// TestQuarantined writes a failed test that's in the quarantine file as a TODO
+// test point, which TAP consumers don't count as a failure.
+func (p *tapReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	node := p.finishTest(testName)
+	node.failed = true
+	node.quarantined = true
+	node.diagnostics = tapFailureDiagnostics(stats)
+}
+
+func (p *tapReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	node := p.finishTest(testName)
+	node.skipped = true
+	node.skipMessage = stats.Message
+}
+
+// finishTest returns the node for a test that has finished, creating it if
+// the test never reported starting.
+func (p *tapReporter) finishTest(testName *TestName) *tapNode {
+	node, ok := p.tests[testName.String()]
+	if !ok {
+		p.TestStarting(testName)
+		node = p.tests[testName.String()]
+	}
+	delete(p.tests, testName.String())
+
+	return node
+}
+
+func (p *tapReporter) write(format string, args ...interface{}) {
+	if p.err != nil || p.out == nil {
+		return
+	}
+
+	_, err := fmt.Fprintf(p.out, format, args...)
+	if err != nil {
+		p.err = err
+		fmt.Fprintf(os.Stderr, "ERROR: Could not write TAP output to %s: %s\n", p.outputPath, err)
+	}
+}
+
+// writeTAPNode writes the test point for node, preceded by its children as a
+// nested subtest if it has any.
+func writeTAPNode(w io.Writer, node *tapNode, number int, indent string) {
+	if len(node.children) > 0 {
+		childIndent := indent + "    "
+		fmt.Fprintf(w, "%s# Subtest: %s\n", childIndent, node.name)
+		for idx, child := range node.children {
+			writeTAPNode(w, child, idx+1, childIndent)
+		}
+		fmt.Fprintf(w, "%s1..%d\n", childIndent, len(node.children))
+	}
+
+	status := "ok"
+	if node.failed {
+		status = "not ok"
+	}
+	fmt.Fprintf(w, "%s%s %d - %s", indent, status, number, tapEscape(node.name))
+	if node.quarantined {
+		fmt.Fprintf(w, " # TODO quarantined")
+	} else if node.skipped {
+		fmt.Fprintf(w, " # SKIP")
+		if node.skipMessage != "" {
+			fmt.Fprintf(w, " %s", tapEscape(firstLine(node.skipMessage)))
+		}
+	}
+	fmt.Fprintf(w, "\n")
+
+	if len(node.diagnostics) > 0 {
+		data, err := yaml.Marshal(node.diagnostics)
+		if err != nil {
+			return
+		}
+
+		yamlIndent := indent + "  "
+		fmt.Fprintf(w, "%s---\n", yamlIndent)
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			fmt.Fprintf(w, "%s%s\n", yamlIndent, line)
+		}
+		fmt.Fprintf(w, "%s...\n", yamlIndent)
+	}
+}
+
+// tapFailureDiagnostics builds the YAML diagnostic block for a failed test,
+// using the keys TAP producers commonly use where there are some.
+func tapFailureDiagnostics(stats *TestFailedStats) yaml.MapSlice {
+	if onlySubtestsFailed(stats) {
+		return yaml.MapSlice{
+			{Key: "message", Value: "One or more subtests failed"},
+			{Key: "severity", Value: "fail"},
+			{Key: "duration_ms", Value: durationMillis(stats.Time.Seconds())},
+		}
+	}
+
+	severity := "fail"
+	if stats.Panicked {
+		severity = "panic"
+	}
+
+	diag := yaml.MapSlice{
+		{Key: "message", Value: stats.Message},
+		{Key: "severity", Value: severity},
+		{Key: "duration_ms", Value: durationMillis(stats.Time.Seconds())},
+	}
+
+	frames := make([]string, 0, len(stats.Frames))
+	var at *TestFailedFrame
+	for _, frame := range stats.Frames {
+		if frame.Hidden {
+			continue
+		}
+		if at == nil {
+			at = frame
+		}
+		frames = append(frames, fmt.Sprintf("%s:%d", frame.File, frame.Line))
+	}
+	if at != nil {
+		diag = append(diag, yaml.MapItem{Key: "at", Value: yaml.MapSlice{
+			{Key: "file", Value: at.File},
+			{Key: "line", Value: at.Line},
+		}})
+		diag = append(diag, yaml.MapItem{Key: "frames", Value: frames})
+	}
+
+	if actual, expected, ok := gomegaValues(stats.Message); ok {
+		diag = append(diag,
+			yaml.MapItem{Key: "found", Value: actual},
+			yaml.MapItem{Key: "wanted", Value: expected},
+		)
+	}
+	if stats.Panicked && stats.Stack != "" {
+		diag = append(diag, yaml.MapItem{Key: "stack", Value: stats.Stack})
+	}
+
+	return diag
+}
+
+// tapEscape escapes the characters that have a meaning in a test point's
+// description.
+func tapEscape(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	return strings.Replace(value, "#", "\\#", -1)
+}
+
+func durationMillis(seconds float64) float64 {
+	return float64(int64(seconds*1000000+0.5)) / 1000
+}
@@ -0,0 +1,132 @@
+package sweet
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type TAPSuite struct{}
+
+func (s *TAPSuite) TestOutput(t T) {
+	out := &bytes.Buffer{}
+
+	p := newTAPReporter()
+	p.out = out
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	subName := newSubtestName(testName, "Sub #1")
+	failName := newTestName("MySuite", []string{"TestFail"})
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestStarting(testName)
+	p.TestStarting(subName)
+	p.TestPassed(subName, &TestPassedStats{Time: time.Millisecond})
+	p.TestPassed(testName, &TestPassedStats{Time: 2 * time.Millisecond})
+	p.TestStarting(failName)
+	p.TestFailed(failName, &TestFailedStats{
+		Name:    failName,
+		Time:    1500 * time.Millisecond,
+		Message: "Expected\n    <int>: 1\nto equal\n    <int>: 2",
+		Frames: []*TestFailedFrame{
+			{File: "/src/my_test.go", Line: 20},
+			{File: "/src/helper.go", Line: 5, Hidden: true},
+		},
+	})
+	p.TestStarting(skipName)
+	p.TestSkipped(skipName, &TestSkippedStats{Message: "not today"})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: 2 * time.Second})
+	p.Finished()
+
+	Expect(out.String()).To(Equal(`TAP version 14
+    # Subtest: MySuite
+        # Subtest: TestThing
+        ok 1 - Sub \#1
+          ---
+          duration_ms: 1
+          ...
+        1..1
+    ok 1 - TestThing
+      ---
+      duration_ms: 2
+      ...
+    not ok 2 - TestFail
+      ---
+      message: |-
+        Expected
+            <int>: 1
+        to equal
+            <int>: 2
+      severity: fail
+      duration_ms: 1500
+      at:
+        file: /src/my_test.go
+        line: 20
+      frames:
+      - /src/my_test.go:20
+      found: |2
+            <int>: 1
+      wanted: |2
+            <int>: 2
+      ...
+    ok 3 - TestSkip # SKIP not today
+    1..3
+not ok 1 - MySuite
+  ---
+  duration_ms: 2000
+  ...
+1..1
+`))
+}
+
+func (s *TAPSuite) TestVersion(t T) {
+	out := &bytes.Buffer{}
+
+	p := newTAPReporter()
+	p.SetOption("version", "13")
+	p.out = out
+
+	p.Starting()
+	p.Finished()
+
+	Expect(out.String()).To(Equal("TAP version 13\n1..0\n"))
+}
+
+func (s *TAPSuite) TestQuarantined(t T) {
+	out := &bytes.Buffer{}
+
+	p := newTAPReporter()
+	p.out = out
+
+	testName := newTestName("MySuite", []string{"TestFlaky"})
+
+	p.Starting()
+	p.SuiteStarting("MySuite")
+	p.TestStarting(testName)
+	p.TestQuarantined(testName, &TestFailedStats{
+		Name:        testName,
+		Message:     "boom",
+		Quarantined: true,
+	})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{})
+	p.Finished()
+
+	Expect(out.String()).To(Equal(`TAP version 14
+    # Subtest: MySuite
+    not ok 1 - TestFlaky # TODO quarantined
+      ---
+      message: boom
+      severity: fail
+      duration_ms: 0
+      ...
+    1..1
+ok 1 - MySuite
+  ---
+  duration_ms: 0
+  ...
+1..1
+`))
+}
@@ -0,0 +1,214 @@
+package sweet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+func init() {
+	RegisterReporter("teamcity", func() Plugin { return NewTeamCityReporter() })
+}
+
+// teamCityReporter writes TeamCity service messages to stdout so a TeamCity
+// build shows each suite and test. Each suite, test and subtest has its own
+// flow, started inside the flow of its suite or parent test, so suites and
+// subtests running in parallel are kept apart.
+type teamCityReporter struct {
+	out io.Writer
+	pkg string
+}
+
+// NewTeamCityReporter creates a reporter writing TeamCity service messages.
+func NewTeamCityReporter() Plugin {
+	return newTeamCityReporter(os.Stdout)
+}
+
+func newTeamCityReporter(out io.Writer) *teamCityReporter {
+	return &teamCityReporter{
+		out: out,
+		pkg: packageImportPath(),
+	}
+}
+
+func (p *teamCityReporter) Name() string {
+	return "TeamCity Reporter"
+}
+
+func (p *teamCityReporter) SuiteStarting(suite string) {
+	flowID := p.suiteFlowID(suite)
+	p.message("flowStarted", flowID)
+	p.message("testSuiteStarted", flowID, "name", suite)
+}
+
+func (p *teamCityReporter) SuiteFinished(suite string, stats *SuiteFinishedStats) {
+	flowID := p.suiteFlowID(suite)
+	p.message("testSuiteFinished", flowID, "name", suite)
+	p.message("flowFinished", flowID)
+}
+
+func (p *teamCityReporter) TestStarting(testName *TestName) {
+	flowID := p.testFlowID(testName)
+	p.message("flowStarted", flowID, "parent", p.parentFlowID(testName))
+	p.message("testStarted", flowID,
+		"name", teamCityTestName(testName),
+		"captureStandardOutput", "false",
+	)
+}
+
+func (p *teamCityReporter) TestPassed(testName *TestName, stats *TestPassedStats) {
+	p.finished(testName, stats.Time.Nanoseconds())
+}
+
+func (p *teamCityReporter) TestFailed(testName *TestName, stats *TestFailedStats) {
+	flowID := p.testFlowID(testName)
+	name := teamCityTestName(testName)
+
+	if onlySubtestsFailed(stats) {
+		names := make([]string, 0, len(stats.FailedSubtests))
+		for _, subtest := range stats.FailedSubtests {
+			names = append(names, teamCityTestName(subtest))
+		}
+		p.message("testFailed", flowID,
+			"name", name,
+			"message", "Failed subtests: "+strings.Join(names, ", "),
+		)
+	} else {
+		details := failureBody(stats)
+		if stats.Panicked && stats.Stack != "" {
+			details += "\n\n" + stats.Stack
+		}
+
+		attrs := []string{
+			"name", name,
+			"message", firstLine(stats.Message),
+			"details", details,
+		}
+		if actual, expected, ok := gomegaValues(stats.Message); ok {
+			attrs = append(attrs,
+				"type", "comparisonFailure",
+				"expected", strings.TrimSpace(expected),
+				"actual", strings.TrimSpace(actual),
+			)
+		}
+		p.message("testFailed", flowID, attrs...)
+	}
+
+	p.finished(testName, stats.Time.Nanoseconds())
+}
+
+// TestQuarantined reports a failed test that's in the quarantine file as
+// ignored so it doesn't fail the build, with the failure written to its
+// stderr.
+func (p *teamCityReporter) TestQuarantined(testName *TestName, stats *TestFailedStats) {
+	flowID := p.testFlowID(testName)
+	name := teamCityTestName(testName)
+
+	if !onlySubtestsFailed(stats) {
+		p.message("testStdErr", flowID, "name", name, "out", failureBody(stats))
+	}
+	p.message("testIgnored", flowID,
+		"name", name,
+		"message", quarantinedMessage(stats),
+	)
+	p.finished(testName, stats.Time.Nanoseconds())
+}
+
+func (p *teamCityReporter) TestSkipped(testName *TestName, stats *TestSkippedStats) {
+	p.message("testIgnored", p.testFlowID(testName),
+		"name", teamCityTestName(testName),
+		"message", stats.Message,
+	)
+	p.finished(testName, stats.Time.Nanoseconds())
+}
+
+func (p *teamCityReporter) TestOutput(testName *TestName, output string) {
+	p.message("testStdOut", p.testFlowID(testName),
+		"name", teamCityTestName(testName),
+		"out", output,
+	)
+}
+
+func (p *teamCityReporter) finished(testName *TestName, nanos int64) {
+	flowID := p.testFlowID(testName)
+	p.message("testFinished", flowID,
+		"name", teamCityTestName(testName),
+		"duration", fmt.Sprintf("%d", nanos/1000000),
+	)
+	p.message("flowFinished", flowID)
+}
+
+// suiteFlowID identifies the suite's messages. The package is included so
+// suites with the same name in different packages aren't mixed up.
+func (p *teamCityReporter) suiteFlowID(suite string) string {
+	return p.pkg + "." + suite
+}
+
+// testFlowID identifies the messages of a test or subtest.
+func (p *teamCityReporter) testFlowID(testName *TestName) string {
+	return p.suiteFlowID(testName.SuiteName) + "/" + teamCityTestName(testName)
+}
+
+// parentFlowID is the flow a test's flow is started in, the flow of its
+// parent test for a subtest or otherwise the flow of its suite.
+func (p *teamCityReporter) parentFlowID(testName *TestName) string {
+	if testName.Parent != nil {
+		return p.testFlowID(testName.Parent)
+	}
+
+	return p.suiteFlowID(testName.SuiteName)
+}
+
+// message writes a service message with the given attribute names and values,
+// followed by the flow ID.
+func (p *teamCityReporter) message(name string, flowID string, attrs ...string) {
+	var msg strings.Builder
+	msg.WriteString("##teamcity[")
+	msg.WriteString(name)
+	for idx := 0; idx+1 < len(attrs); idx += 2 {
+		fmt.Fprintf(&msg, " %s='%s'", attrs[idx], teamCityEscape(attrs[idx+1]))
+	}
+	fmt.Fprintf(&msg, " flowId='%s']\n", teamCityEscape(flowID))
+
+	io.WriteString(p.out, msg.String())
+}
+
+func teamCityTestName(testName *TestName) string {
+	return strings.Join(testName.TestNames, "/")
+}
+
+// teamCityEscape escapes a value for use in a service message attribute.
+func teamCityEscape(value string) string {
+	var res strings.Builder
+	for _, r := range value {
+		switch r {
+		case '|':
+			res.WriteString("||")
+		case '\'':
+			res.WriteString("|'")
+		case '\n':
+			res.WriteString("|n")
+		case '\r':
+			res.WriteString("|r")
+		case '[':
+			res.WriteString("|[")
+		case ']':
+			res.WriteString("|]")
+		default:
+			if r > 0xffff {
+				// Escapes take exactly four digits so characters outside
+				// the BMP are written as a UTF-16 surrogate pair
+				r1, r2 := utf16.EncodeRune(r)
+				fmt.Fprintf(&res, "|0x%04x|0x%04x", r1, r2)
+			} else if r > 0x7f {
+				fmt.Fprintf(&res, "|0x%04x", r)
+			} else {
+				res.WriteRune(r)
+			}
+		}
+	}
+
+	return res.String()
+}
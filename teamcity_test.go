@@ -0,0 +1,109 @@
+package sweet
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type TeamCitySuite struct{}
+
+func (s *TeamCitySuite) TestMessages(t T) {
+	out := &bytes.Buffer{}
+	p := newTeamCityReporter(out)
+	p.pkg = "pkg"
+
+	testName := newTestName("MySuite", []string{"TestThing"})
+	subName := newSubtestName(testName, "Sub")
+	passName := newTestName("MySuite", []string{"TestPass"})
+	skipName := newTestName("MySuite", []string{"TestSkip"})
+
+	p.SuiteStarting("MySuite")
+	p.TestStarting(passName)
+	p.TestOutput(passName, "it's [done]")
+	p.TestPassed(passName, &TestPassedStats{Time: 1500 * time.Millisecond})
+	p.TestStarting(testName)
+	p.TestStarting(subName)
+	p.TestFailed(subName, &TestFailedStats{
+		Name:    subName,
+		Time:    2 * time.Millisecond,
+		Message: "Expected\n    <int>: 1\nto equal\n    <int>: 2",
+		Frames: []*TestFailedFrame{
+			{File: "/src/my_test.go", Line: 20},
+		},
+	})
+	p.TestFailed(testName, &TestFailedStats{
+		Name:           testName,
+		Time:           3 * time.Millisecond,
+		FailedSubtests: []*TestName{subName},
+	})
+	p.TestStarting(skipName)
+	p.TestSkipped(skipName, &TestSkippedStats{Message: "not today"})
+	p.SuiteFinished("MySuite", &SuiteFinishedStats{Time: time.Second})
+
+	const (
+		suiteFlow = "flowId='pkg.MySuite'"
+		passFlow  = "flowId='pkg.MySuite/TestPass'"
+		testFlow  = "flowId='pkg.MySuite/TestThing'"
+		subFlow   = "flowId='pkg.MySuite/TestThing/Sub'"
+		skipFlow  = "flowId='pkg.MySuite/TestSkip'"
+	)
+	Expect(strings.Split(strings.TrimSpace(out.String()), "\n")).To(Equal([]string{
+		"##teamcity[flowStarted " + suiteFlow + "]",
+		"##teamcity[testSuiteStarted name='MySuite' " + suiteFlow + "]",
+		"##teamcity[flowStarted parent='pkg.MySuite' " + passFlow + "]",
+		"##teamcity[testStarted name='TestPass' captureStandardOutput='false' " + passFlow + "]",
+		"##teamcity[testStdOut name='TestPass' out='it|'s |[done|]' " + passFlow + "]",
+		"##teamcity[testFinished name='TestPass' duration='1500' " + passFlow + "]",
+		"##teamcity[flowFinished " + passFlow + "]",
+		"##teamcity[flowStarted parent='pkg.MySuite' " + testFlow + "]",
+		"##teamcity[testStarted name='TestThing' captureStandardOutput='false' " + testFlow + "]",
+		"##teamcity[flowStarted parent='pkg.MySuite/TestThing' " + subFlow + "]",
+		"##teamcity[testStarted name='TestThing/Sub' captureStandardOutput='false' " + subFlow + "]",
+		"##teamcity[testFailed name='TestThing/Sub' message='Expected' " +
+			"details='/src/my_test.go:20|n|nExpected|n    <int>: 1|nto equal|n    <int>: 2' " +
+			"type='comparisonFailure' expected='<int>: 2' actual='<int>: 1' " + subFlow + "]",
+		"##teamcity[testFinished name='TestThing/Sub' duration='2' " + subFlow + "]",
+		"##teamcity[flowFinished " + subFlow + "]",
+		"##teamcity[testFailed name='TestThing' message='Failed subtests: TestThing/Sub' " + testFlow + "]",
+		"##teamcity[testFinished name='TestThing' duration='3' " + testFlow + "]",
+		"##teamcity[flowFinished " + testFlow + "]",
+		"##teamcity[flowStarted parent='pkg.MySuite' " + skipFlow + "]",
+		"##teamcity[testStarted name='TestSkip' captureStandardOutput='false' " + skipFlow + "]",
+		"##teamcity[testIgnored name='TestSkip' message='not today' " + skipFlow + "]",
+		"##teamcity[testFinished name='TestSkip' duration='0' " + skipFlow + "]",
+		"##teamcity[flowFinished " + skipFlow + "]",
+		"##teamcity[testSuiteFinished name='MySuite' " + suiteFlow + "]",
+		"##teamcity[flowFinished " + suiteFlow + "]",
+	}))
+}
+
+func (s *TeamCitySuite) TestEscape(t T) {
+	Expect(teamCityEscape("a|b'c\nd\re[f]g")).To(Equal("a||b|'c|nd|re|[f|]g"))
+	Expect(teamCityEscape("café")).To(Equal("caf|0x00e9"))
+	Expect(teamCityEscape("ok 🎉")).To(Equal("ok |0xd83c|0xdf89"))
+}
+
+func (s *TeamCitySuite) TestQuarantined(t T) {
+	out := &bytes.Buffer{}
+	p := newTeamCityReporter(out)
+	p.pkg = "pkg"
+
+	testName := newTestName("MySuite", []string{"TestFlaky"})
+
+	p.TestQuarantined(testName, &TestFailedStats{
+		Name:        testName,
+		Time:        time.Millisecond,
+		Message:     "boom\nagain",
+		Quarantined: true,
+	})
+
+	Expect(strings.Split(strings.TrimSpace(out.String()), "\n")).To(Equal([]string{
+		"##teamcity[testStdErr name='TestFlaky' out='boom|nagain' flowId='pkg.MySuite/TestFlaky']",
+		"##teamcity[testIgnored name='TestFlaky' message='Quarantined: boom' flowId='pkg.MySuite/TestFlaky']",
+		"##teamcity[testFinished name='TestFlaky' duration='1' flowId='pkg.MySuite/TestFlaky']",
+		"##teamcity[flowFinished flowId='pkg.MySuite/TestFlaky']",
+	}))
+}